@@ -7,23 +7,27 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/chmele/ibm-soar/soar"
 	"github.com/chmele/ibm-soar/soar/structures"
 )
 
 type HTTPInputs struct {
-	Method string `json:"http_method"`
-	Url    string `json:"http_url"`
+	Method string `json:"http_method" input:"required"`
+	Url    string `json:"http_url" input:"required"`
 }
 
+type HTTPResult struct {
+	StatusCode int    `json:"status_code"`
+	Body       string `json:"body"`
+}
 
-func HTTPRequest(fc *structures.FunctionCall) (*structures.FuncResponse, error) {
-	inputs, err := soar.LoadInputs[HTTPInputs](fc)
-	if err != nil {
-		return nil, err
-	}
-	request, err := http.NewRequest(inputs.Method, inputs.Url, nil)
+func HTTPRequest(ctx context.Context, fc *structures.FunctionCall, inputs HTTPInputs) (*HTTPResult, error) {
+	request, err := http.NewRequestWithContext(ctx, inputs.Method, inputs.Url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -31,11 +35,12 @@ func HTTPRequest(fc *structures.FunctionCall) (*structures.FuncResponse, error)
 	if err != nil {
 		return nil, err
 	}
+	defer response.Body.Close()
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
 	}
-	return soar.SuccessResponse(string(body)), nil
+	return &HTTPResult{StatusCode: response.StatusCode, Body: string(body)}, nil
 }
 
 func main() {
@@ -44,6 +49,7 @@ func main() {
 	ipAddr := flag.String("ip", "", "Server IP address")
 	insecure := flag.Bool("insecure", true, "Use insecure connection")
 	destination := flag.String("destination", "http", "STOMP message destination")
+	org := flag.String("org", "", "Organization name, ID or UUID, required for multi-org credentials")
 
 	flag.Parse()
 
@@ -53,7 +59,7 @@ ipAddr)
 	}
 
 	ctx := context.Background()
-	client, err := soar.NewHTTPClient(ctx, *ipAddr, *tokenId, *tokenSecret, *insecure)
+	client, err := soar.NewHTTPClient(ctx, *ipAddr, *tokenId, *tokenSecret, *insecure, soar.HTTP.Org(*org))
 	if err != nil {
 		log.Fatalf("Error while checking connectivity: %v", err)
 	}
@@ -63,14 +69,29 @@ ipAddr)
 		soar.Stomp.Insecure(*insecure),
 		soar.Stomp.Logger(slog.Default()),
 		soar.Stomp.MessageDestination(*destination),
+		soar.Stomp.Timeout(time.Minute),
 	)
 	if err != nil {
 		log.Fatalf("Error creating STOMP listener: %v", err)
 	}
 
-	err = stompListener.Listen(soar.LoggingResponse, HTTPRequest)
+	err = stompListener.ListenContext(soar.WithContext(soar.LoggingResponse), soar.Typed(HTTPRequest))
 	if err != nil {
 		log.Fatalf("Error while STOMPing: %v", err)
 	}
-	<-stompListener.Done
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-signals:
+		shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		aborted, err := stompListener.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Printf("Aborted %d function calls: %v", len(aborted), err)
+		}
+	case <-stompListener.Done:
+		if stompListener.Err != nil {
+			log.Fatalf("Error while STOMPing: %v", stompListener.Err)
+		}
+	}
 }
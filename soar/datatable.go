@@ -0,0 +1,248 @@
+package soar
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Maps a struct to data table cells and back using `soar:"column_api_name"` field tags:
+//
+//	type Hit struct {
+//		RowID   int       `soar:",rowid"`
+//		IP      string    `soar:"ip_address"`
+//		Seen    time.Time `soar:"last_seen"`
+//		Verdict string    `soar:"verdict"` // select column, option label
+//		Blocked bool      `soar:"blocked,omitempty"`
+//	}
+//
+// time.Time is sent as milliseconds since epoch, select and multiselect columns are set
+// from option labels (string, []string) or IDs. Untagged fields are ignored
+func MarshalCells(v any, columns []structures.FieldDefinition) (map[string]structures.Cell, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	defs := columnsByName(columns)
+	cells := map[string]structures.Cell{}
+	for i := range rv.NumField() {
+		tag, ok := parseColumnTag(rv.Type().Field(i))
+		if !ok || tag.rowID {
+			continue
+		}
+		fv := rv.Field(i)
+		if tag.omitEmpty && fv.IsZero() {
+			continue
+		}
+		value, err := cellValue(fv, defs[tag.name])
+		if err != nil {
+			return nil, fmt.Errorf("Column %s: %w", tag.name, err)
+		}
+		cells[tag.name] = structures.Cell{Value: value}
+	}
+	return cells, nil
+}
+
+// Fills the tagged fields of the struct pointed to by v from the row, see MarshalCells
+func UnmarshalRow(row *structures.DataTableRow, v any, columns []structures.FieldDefinition) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Expected a pointer to struct, got %T", v)
+	}
+	rv = rv.Elem()
+	defs := columnsByName(columns)
+	for i := range rv.NumField() {
+		tag, ok := parseColumnTag(rv.Type().Field(i))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if tag.rowID {
+			if fv.CanInt() {
+				fv.SetInt(int64(row.ID))
+			}
+			continue
+		}
+		cell, ok := row.Cells[tag.name]
+		if !ok || cell.Value == nil {
+			fv.SetZero()
+			continue
+		}
+		if err := setField(fv, cell.Value, defs[tag.name]); err != nil {
+			return fmt.Errorf("Column %s: %w", tag.name, err)
+		}
+	}
+	return nil
+}
+
+type columnTag struct {
+	name      string
+	omitEmpty bool
+	rowID     bool
+}
+
+func parseColumnTag(f reflect.StructField) (columnTag, bool) {
+	tag, ok := f.Tag.Lookup("soar")
+	if !ok || tag == "-" || !f.IsExported() {
+		return columnTag{}, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	ret := columnTag{name: name}
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "omitempty":
+			ret.omitEmpty = true
+		case "rowid":
+			ret.rowID = true
+		}
+	}
+	return ret, ret.name != "" || ret.rowID
+}
+
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("Expected a struct, got %T", v)
+	}
+	return rv, nil
+}
+
+func columnsByName(columns []structures.FieldDefinition) map[string]*structures.FieldDefinition {
+	defs := make(map[string]*structures.FieldDefinition, len(columns))
+	for i := range columns {
+		defs[columns[i].Name] = &columns[i]
+	}
+	return defs
+}
+
+func isSelect(def *structures.FieldDefinition) bool {
+	return def != nil && (def.InputType == "select" || def.InputType == "multiselect")
+}
+
+// Cell value of the field in the form SOAR expects
+func cellValue(fv reflect.Value, def *structures.FieldDefinition) (any, error) {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	switch v := fv.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil, nil
+		}
+		return v.UnixMilli(), nil
+	case string:
+		if isSelect(def) {
+			if v == "" {
+				return nil, nil
+			}
+			return optionID(def, v)
+		}
+	case []string:
+		if isSelect(def) {
+			ids := make([]int, len(v))
+			for i, label := range v {
+				id, err := optionID(def, label)
+				if err != nil {
+					return nil, err
+				}
+				ids[i] = id
+			}
+			return ids, nil
+		}
+	}
+	return fv.Interface(), nil
+}
+
+// Sets the field from a decoded JSON cell value
+func setField(fv reflect.Value, value any, def *structures.FieldDefinition) error {
+	if fv.Kind() == reflect.Pointer {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setField(ptr.Elem(), value, def); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	switch fv.Interface().(type) {
+	case time.Time:
+		ms, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("Expected a date in milliseconds, got %v", value)
+		}
+		fv.Set(reflect.ValueOf(time.UnixMilli(int64(ms))))
+		return nil
+	case bool:
+		// boolean columns may hold "true"/"false" strings
+		if s, ok := value.(string); ok {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return fmt.Errorf("Expected a boolean, got %q", s)
+			}
+			fv.SetBool(b)
+			return nil
+		}
+	case string:
+		if id, ok := value.(float64); ok && isSelect(def) {
+			label, err := optionLabel(def, int(id))
+			if err != nil {
+				return err
+			}
+			fv.SetString(label)
+			return nil
+		}
+	case []string:
+		if ids, ok := value.([]any); ok && isSelect(def) {
+			labels := make([]string, 0, len(ids))
+			for _, id := range ids {
+				n, ok := id.(float64)
+				if !ok {
+					return fmt.Errorf("Expected option IDs, got %v", value)
+				}
+				label, err := optionLabel(def, int(n))
+				if err != nil {
+					return err
+				}
+				labels = append(labels, label)
+			}
+			fv.Set(reflect.ValueOf(labels))
+			return nil
+		}
+	}
+	// numbers, booleans, strings and anything else JSON can convert
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, fv.Addr().Interface())
+}
+
+func optionID(def *structures.FieldDefinition, label string) (int, error) {
+	labels := make([]string, 0, len(def.Values))
+	for _, v := range def.Values {
+		if strings.EqualFold(v.Label, label) {
+			return v.Value, nil
+		}
+		labels = append(labels, v.Label)
+	}
+	return 0, fmt.Errorf("Unknown option %q, available: %s", label, strings.Join(labels, ", "))
+}
+
+func optionLabel(def *structures.FieldDefinition, id int) (string, error) {
+	for _, v := range def.Values {
+		if v.Value == id {
+			return v.Label, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown option ID %d", id)
+}
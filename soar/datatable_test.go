@@ -0,0 +1,95 @@
+package soar
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+type testHit struct {
+	RowID   int       `soar:",rowid"`
+	IP      string    `soar:"ip_address"`
+	Seen    time.Time `soar:"last_seen"`
+	Verdict string    `soar:"verdict"`
+	Tags    []string  `soar:"tags"`
+	Blocked bool      `soar:"blocked"`
+	Score   *float64  `soar:"score,omitempty"`
+	Note    string
+}
+
+var testColumns = []structures.FieldDefinition{
+	{Name: "ip_address", InputType: "text"},
+	{Name: "last_seen", InputType: "datetimepicker"},
+	{Name: "verdict", InputType: "select", Values: []structures.FieldValue{{Value: 31, Label: "Malicious"}, {Value: 32, Label: "Clean"}}},
+	{Name: "tags", InputType: "multiselect", Values: []structures.FieldValue{{Value: 41, Label: "C2"}, {Value: 42, Label: "Tor"}}},
+	{Name: "blocked", InputType: "boolean"},
+	{Name: "score", InputType: "number"},
+}
+
+func TestMarshalCells(t *testing.T) {
+	hit := testHit{
+		IP:      "10.0.0.1",
+		Seen:    time.UnixMilli(1700000000000),
+		Verdict: "malicious",
+		Tags:    []string{"Tor", "C2"},
+		Blocked: true,
+		Note:    "ignored",
+	}
+	cells, err := MarshalCells(&hit, testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]structures.Cell{
+		"ip_address": {Value: "10.0.0.1"},
+		"last_seen":  {Value: int64(1700000000000)},
+		"verdict":    {Value: 31},
+		"tags":       {Value: []int{42, 41}},
+		"blocked":    {Value: true},
+	}
+	if !reflect.DeepEqual(cells, want) {
+		t.Errorf("Unexpected cells:\n%v\nwant\n%v", cells, want)
+	}
+
+	hit.Verdict = "Suspicious"
+	if _, err := MarshalCells(hit, testColumns); err == nil {
+		t.Error("Expected unknown option error")
+	}
+	if _, err := MarshalCells("not a struct", testColumns); err == nil {
+		t.Error("Expected struct type error")
+	}
+}
+
+func TestUnmarshalRow(t *testing.T) {
+	var row structures.DataTableRow
+	err := json.Unmarshal([]byte(`{"id": 7, "cells": {
+		"ip_address": {"id": "ip_address", "value": "10.0.0.1"},
+		"last_seen": {"value": 1700000000000},
+		"verdict": {"value": 32},
+		"tags": {"value": [41]},
+		"blocked": {"value": "true"},
+		"score": {"value": 9.5}
+	}}`), &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hit testHit
+	if err := UnmarshalRow(&row, &hit, testColumns); err != nil {
+		t.Fatal(err)
+	}
+	score := 9.5
+	want := testHit{
+		RowID:   7,
+		IP:      "10.0.0.1",
+		Seen:    time.UnixMilli(1700000000000),
+		Verdict: "Clean",
+		Tags:    []string{"C2"},
+		Blocked: true,
+		Score:   &score,
+	}
+	if !reflect.DeepEqual(hit, want) {
+		t.Errorf("Unexpected row:\n%+v\nwant\n%+v", hit, want)
+	}
+}
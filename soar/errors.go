@@ -0,0 +1,129 @@
+package soar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+var (
+	// Message destination does not exist in the organization
+	ErrDestinationNotFound = errors.New("message destination not found")
+	// API key is valid, but is not allowed to use the message destination
+	ErrAPIKeyNotAuthorized = errors.New("API key is not authorized for the message destination")
+	// SOAR rejected the credentials
+	ErrUnauthorized = errors.New("SOAR rejected the credentials")
+	// STOMP listener logs in with API key credentials only, user sessions are not accepted
+	ErrAPIKeyRequired = errors.New("STOMP listener requires API key credentials")
+	// No organization of the session matches the selector
+	ErrOrgNotFound = errors.New("organization not found")
+	// Session has several organizations and none was selected
+	ErrAmbiguousOrg = errors.New("several organizations available, select one")
+	// Fields required to close an incident have no values
+	ErrMissingCloseFields = errors.New("fields required to close the incident are not set")
+)
+
+// Non-2xx response of SOAR REST API, along with the error body SOAR sends
+type APIError struct {
+	StatusCode int      `json:"-"`
+	Method     string   `json:"-"`
+	Path       string   `json:"-"`
+	Success    bool     `json:"success"`
+	Title      string   `json:"title"`
+	Message    string   `json:"message"`
+	Hints      []string `json:"hints"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SOAR API error %d", e.StatusCode)
+	if e.Method != "" {
+		fmt.Fprintf(&b, " on %s %s", e.Method, e.Path)
+	}
+	if e.Title != "" {
+		fmt.Fprintf(&b, ": %s", e.Title)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if len(e.Hints) > 0 {
+		fmt.Fprintf(&b, " (hints: %s)", strings.Join(e.Hints, "; "))
+	}
+	return b.String()
+}
+
+// Makes errors.Is(err, ErrUnauthorized) hold for 401 responses
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnauthorized && e.StatusCode == http.StatusUnauthorized
+}
+
+// PATCH rejected because the fields were changed since the object was read, see RetryConflict
+type ConflictError struct {
+	Title   string
+	Message string
+	Fields  []structures.FieldFailure
+}
+
+func (e *ConflictError) Error() string {
+	names := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		names[i] = f.Field.Name
+	}
+	msg := "Conflicting changes of fields " + strings.Join(names, ", ")
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Whether err is an APIError with the given status code
+func IsStatus(err error, code int) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.StatusCode == code
+}
+
+func IsNotFound(err error) bool     { return IsStatus(err, http.StatusNotFound) }
+func IsForbidden(err error) bool    { return IsStatus(err, http.StatusForbidden) }
+func IsUnauthorized(err error) bool { return IsStatus(err, http.StatusUnauthorized) }
+
+// Whether SOAR rejected a change as conflicting: a 409 response or a failed PATCH (ConflictError)
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return IsStatus(err, http.StatusConflict) || errors.As(err, &conflict)
+}
+
+// Returns APIError for non-2xx responses, consuming the body
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	e := &APIError{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.Path = resp.Request.URL.Path
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(body, e); err != nil {
+		// not a SOAR error body (proxy pages, plain text), keep it as the message
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// Decodes a successful JSON response into T, closing the body
+func decodeResponse[T any](resp *http.Response) (*T, error) {
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	ret := new(T)
+	if err := json.NewDecoder(resp.Body).Decode(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
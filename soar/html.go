@@ -0,0 +1,128 @@
+package soar
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Builds note HTML from untrusted values: all text is escaped and only http(s)/mailto links are kept
+//
+//	note := soar.NewHTML().Heading("VirusTotal").Table([]string{"Engine", "Verdict"}, rows).Note()
+type HTMLBuilder struct {
+	b strings.Builder
+}
+
+func NewHTML() *HTMLBuilder {
+	return &HTMLBuilder{}
+}
+
+// Escaped text
+func (h *HTMLBuilder) Text(s string) *HTMLBuilder {
+	h.b.WriteString(html.EscapeString(s))
+	return h
+}
+
+// Formatted escaped text, arguments are escaped after formatting
+func (h *HTMLBuilder) Textf(format string, args ...any) *HTMLBuilder {
+	return h.Text(fmt.Sprintf(format, args...))
+}
+
+func (h *HTMLBuilder) Bold(s string) *HTMLBuilder {
+	return h.wrap("b", s)
+}
+
+func (h *HTMLBuilder) Italic(s string) *HTMLBuilder {
+	return h.wrap("i", s)
+}
+
+func (h *HTMLBuilder) Code(s string) *HTMLBuilder {
+	return h.wrap("code", s)
+}
+
+func (h *HTMLBuilder) Heading(s string) *HTMLBuilder {
+	return h.wrap("h3", s)
+}
+
+func (h *HTMLBuilder) Paragraph(s string) *HTMLBuilder {
+	return h.wrap("p", s)
+}
+
+func (h *HTMLBuilder) LineBreak() *HTMLBuilder {
+	h.b.WriteString("<br>")
+	return h
+}
+
+// Link to an http, https or mailto URL, other URLs (javascript: etc.) are written as plain text
+func (h *HTMLBuilder) Link(href, text string) *HTMLBuilder {
+	u, err := url.Parse(href)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+		return h.Text(text)
+	}
+	fmt.Fprintf(&h.b, `<a href="%s" target="_blank">%s</a>`, html.EscapeString(u.String()), html.EscapeString(text))
+	return h
+}
+
+// Bulleted list
+func (h *HTMLBuilder) List(items ...string) *HTMLBuilder {
+	h.b.WriteString("<ul>")
+	for _, item := range items {
+		h.wrap("li", item)
+	}
+	h.b.WriteString("</ul>")
+	return h
+}
+
+// Table with a header row, rows shorter than headers are padded with empty cells
+func (h *HTMLBuilder) Table(headers []string, rows [][]string) *HTMLBuilder {
+	h.b.WriteString(`<table border="1"><tr>`)
+	for _, header := range headers {
+		h.wrap("th", header)
+	}
+	h.b.WriteString("</tr>")
+	for _, row := range rows {
+		h.b.WriteString("<tr>")
+		for i := range max(len(headers), len(row)) {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			h.wrap("td", cell)
+		}
+		h.b.WriteString("</tr>")
+	}
+	h.b.WriteString("</table>")
+	return h
+}
+
+// Two-column table of names and values, e.g. enrichment results of a single artifact
+func (h *HTMLBuilder) KeyValues(pairs ...[2]string) *HTMLBuilder {
+	rows := make([][]string, len(pairs))
+	for i, p := range pairs {
+		rows[i] = p[:]
+	}
+	return h.Table([]string{"Name", "Value"}, rows)
+}
+
+// Trusted markup written as is
+func (h *HTMLBuilder) Raw(markup string) *HTMLBuilder {
+	h.b.WriteString(markup)
+	return h
+}
+
+func (h *HTMLBuilder) String() string {
+	return h.b.String()
+}
+
+// HTML note content
+func (h *HTMLBuilder) Note() structures.TextContent {
+	return HTMLNote(h.String())
+}
+
+func (h *HTMLBuilder) wrap(tag, s string) *HTMLBuilder {
+	fmt.Fprintf(&h.b, "<%s>%s</%s>", tag, html.EscapeString(s), tag)
+	return h
+}
@@ -0,0 +1,26 @@
+package soar
+
+import "testing"
+
+func TestHTMLBuilder(t *testing.T) {
+	got := NewHTML().
+		Heading("Results for <host>").
+		Table([]string{"Engine", "Verdict"}, [][]string{{"A&B", "<script>alert(1)</script>"}, {"C"}}).
+		Link("javascript:alert(1)", "click").
+		Link("https://example.com/?q=a&b=c", "report").
+		List("one", "\"two\"").
+		String()
+	want := `<h3>Results for &lt;host&gt;</h3>` +
+		`<table border="1"><tr><th>Engine</th><th>Verdict</th></tr>` +
+		`<tr><td>A&amp;B</td><td>&lt;script&gt;alert(1)&lt;/script&gt;</td></tr>` +
+		`<tr><td>C</td><td></td></tr></table>` +
+		`click` +
+		`<a href="https://example.com/?q=a&amp;b=c" target="_blank">report</a>` +
+		`<ul><li>one</li><li>&#34;two&#34;</li></ul>`
+	if got != want {
+		t.Errorf("Unexpected HTML:\n%s\nwant\n%s", got, want)
+	}
+	if note := NewHTML().Bold("x").Note(); note.Format != "html" || note.Content != "<b>x</b>" {
+		t.Errorf("Unexpected note content: %+v", note)
+	}
+}
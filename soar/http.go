@@ -1,15 +1,18 @@
 package soar
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"slices"
+	"strings"
 	"time"
 	"github.com/chmele/ibm-soar/soar/structures"
 )
@@ -22,39 +25,116 @@ type HTTPClient struct {
 	KeySecret string
 	Hostname  string
 	Ctx       context.Context
+	Retry     *RetryPolicy
+	// Timeout of attachment uploads and downloads, unlimited if 0 (Ctx still applies)
+	FileTimeout time.Duration
+	// Credentials of REST requests, API key Basic auth with KeyId and KeySecret if nil
+	Auth Authenticator
+	// TLS settings shared by REST and STOMP connections
+	TLSConfig *tls.Config
+	// HTTPS port, 443 if empty
+	Port string
+	// Path prefix in front of /rest/, for SOAR behind a reverse proxy
+	BasePath    string
+	UserAgent   string
+	proxy       func(*http.Request) (*url.URL, error)
+	orgSelector string
+	cache       *definitionCache
 }
 
-func NewHTTPClient(ctx context.Context, hostname, keyId, keySecret string, insecure bool) (*HTTPClient, error) {
+func NewHTTPClient(ctx context.Context, hostname, keyId, keySecret string, insecure bool, opts ...HTTPOption) (*HTTPClient, error) {
+	retry := DefaultRetryPolicy
 	ret := &HTTPClient{
 		KeyId:     keyId,
 		KeySecret: keySecret,
 		Hostname:  hostname,
 		Ctx:       ctx,
+		Retry:     &retry,
+		TLSConfig: &tls.Config{InsecureSkipVerify: insecure},
+		cache:     &definitionCache{entries: map[string]any{}},
 		Client: http.Client{
 			Timeout: 5 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-			},
 		},
 	}
+	for _, opt := range opts {
+		if err := opt(ret); err != nil {
+			return nil, err
+		}
+	}
+	ret.Client.Transport = &http.Transport{
+		TLSClientConfig: ret.TLSConfig,
+		Proxy:           ret.proxy,
+	}
 	session, err := ret.GetOrg()
 	if err != nil {
 		return nil, err
 	}
 	ret.Session = session
-	ret.Org = &session.Orgs[0]
+	if err := ret.SelectOrg(ret.orgSelector); err != nil {
+		return nil, err
+	}
 	return ret, nil
 }
 
 func (s *HTTPClient) Request(method, url string, data io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, fmt.Sprintf("https://%s/rest/", s.Hostname)+url, data)
+	return s.send(method, url, data, "application/json")
+}
+
+// Authenticated request with a body of the given content type
+func (s *HTTPClient) send(method, url string, data io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(s.Ctx, method, s.baseURL()+url, data)
 	if err != nil {
 		return nil, err
 	}
-	req = req.WithContext(s.Ctx)
-	auth := base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "%s:%s", s.KeyId, s.KeySecret))
-	req.Header.Add("Authorization", fmt.Sprintf("Basic %s", auth))
-	return s.Client.Do(req)
+	if data != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+	auth := s.authenticator()
+	if err := auth.Authenticate(s, req); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !auth.Invalidate(req) {
+		return resp, err
+	}
+	// session expired, log in again and repeat the request once
+	if data != nil {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		if req.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	req.Header.Del("Cookie")
+	if err := auth.Authenticate(s, req); err != nil {
+		return nil, err
+	}
+	return s.do(req)
+}
+
+func (s *HTTPClient) authenticator() Authenticator {
+	if s.Auth != nil {
+		return s.Auth
+	}
+	return APIKeyAuth{KeyId: s.KeyId, KeySecret: s.KeySecret}
+}
+
+// https://host[:port][/base path]/rest/
+func (s *HTTPClient) baseURL() string {
+	host := s.Hostname
+	if s.Port != "" {
+		host = net.JoinHostPort(s.Hostname, s.Port)
+	}
+	path := "/rest/"
+	if base := strings.Trim(s.BasePath, "/"); base != "" {
+		path = "/" + base + path
+	}
+	return "https://" + host + path
 }
 
 func (s *HTTPClient) GetOrg() (session *structures.SessionResponseJson, err error) {
@@ -62,13 +142,9 @@ func (s *HTTPClient) GetOrg() (session *structures.SessionResponseJson, err erro
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Error connecting to SOAR, status: %s", resp.Status)
-	}
-	body := new(structures.SessionResponseJson)
-	if err := json.NewDecoder(resp.Body).Decode(body); err != nil {
-		return nil, err
+	body, err := decodeResponse[structures.SessionResponseJson](resp)
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to SOAR: %w", err)
 	}
 	if len(body.Orgs) < 1 {
 		return nil, errors.New("API key is not associated with any organization")
@@ -80,27 +156,89 @@ func (s *HTTPClient) OrgRequest(method, url string, data io.Reader) (*http.Respo
 	return s.Request(method, fmt.Sprintf("orgs/%d/%s", s.Org.ID, url), data)
 }
 
-func (s *HTTPClient) GetMessageDestinationAvailable(name string) (bool, error) {
+// Sends in (if not nil) as a JSON body of an org request and decodes the JSON response into T
+func orgJSON[T any](s *HTTPClient, method, url string, in any) (*T, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	resp, err := s.OrgRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse[T](resp)
+}
+
+// Org request whose response body is of no interest, non-2xx status is returned as APIError
+func (s *HTTPClient) orgCall(method, url string, in any) error {
+	_, err := orgJSON[json.RawMessage](s, method, url, in)
+	if errors.Is(err, io.EOF) {
+		// empty body
+		return nil
+	}
+	return err
+}
+
+func (s *HTTPClient) GetMessageDestination(name string) (*structures.MessageDestination, error) {
 	resp, err := s.OrgRequest("GET", "message_destinations/"+name, nil)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	md, err := decodeResponse[structures.MessageDestination](resp)
+	switch {
+	case IsNotFound(err):
+		return nil, fmt.Errorf("%w: %s: %w", ErrDestinationNotFound, name, err)
+	case IsForbidden(err):
+		return nil, fmt.Errorf("%w: %s: %w", ErrAPIKeyNotAuthorized, name, err)
 	}
-	defer resp.Body.Close()
-	var md structures.MessageDestination
-	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+	return md, err
+}
+
+func (s *HTTPClient) GetMessageDestinationAvailable(name string) (bool, error) {
+	md, err := s.GetMessageDestination(name)
+	if err != nil {
 		return false, err
 	}
 	return slices.Contains(md.APIKeys, s.Session.APIKeyHandle), nil
 }
 
+// Checks that the message destination exists and the API key is among its users,
+// returns ErrDestinationNotFound, ErrAPIKeyNotAuthorized or ErrUnauthorized otherwise
+func (s *HTTPClient) CheckMessageDestination(name string) error {
+	ok, err := s.GetMessageDestinationAvailable(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s (API key handle %d)", ErrAPIKeyNotAuthorized, name, s.Session.APIKeyHandle)
+	}
+	return nil
+}
+
+// Lists functions defined in the organization, e.g. to validate a FunctionLookup against its message destination
+func (s *HTTPClient) GetFunctions() ([]structures.FunctionDefinition, error) {
+	resp, err := s.OrgRequest("GET", "functions", nil)
+	if err != nil {
+		return nil, err
+	}
+	list, err := decodeResponse[structures.FunctionList](resp)
+	if err != nil {
+		return nil, err
+	}
+	return list.Entities, nil
+}
+
 func (s *HTTPClient) GetInboundDestinationAvailable(name string) (bool, error) {
 	resp, err := s.OrgRequest("GET", "inbound_destinations/"+name, nil)
 	if err != nil {
 		return false, err
 	}
-	defer resp.Body.Close()
-	var md structures.InboundDestination
-	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+	md, err := decodeResponse[structures.InboundDestination](resp)
+	if err != nil {
 		return false, err
 	}
 	return slices.Contains(md.ReadPrincipals, s.Session.APIKeyHandle) && slices.Contains(md.WritePrincipals, s.Session.APIKeyHandle), nil
@@ -0,0 +1,88 @@
+package soar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func (s *HTTPClient) GetArtifacts(incID int) ([]structures.Artifact, error) {
+	artifacts, err := orgJSON[[]structures.Artifact](s, "GET", fmt.Sprintf("incidents/%d/artifacts", incID), nil)
+	if err != nil {
+		return nil, err
+	}
+	return *artifacts, nil
+}
+
+func (s *HTTPClient) GetArtifact(incID, id int) (*structures.Artifact, error) {
+	return orgJSON[structures.Artifact](s, "GET", fmt.Sprintf("incidents/%d/artifacts/%d", incID, id), nil)
+}
+
+// Creates the artifact, SOAR may split a value into several artifacts so all of them are returned
+func (s *HTTPClient) CreateArtifact(incID int, a *structures.Artifact) ([]structures.Artifact, error) {
+	created, err := orgJSON[[]structures.Artifact](s, "POST", fmt.Sprintf("incidents/%d/artifacts", incID), a)
+	if err != nil {
+		return nil, err
+	}
+	return *created, nil
+}
+
+// Creates the artifacts one by one, stopping at the first failure; the artifacts created
+// before it are returned along with the error
+func (s *HTTPClient) CreateArtifacts(incID int, artifacts []structures.Artifact) ([]structures.Artifact, error) {
+	var ret []structures.Artifact
+	for i := range artifacts {
+		created, err := s.CreateArtifact(incID, &artifacts[i])
+		if err != nil {
+			return ret, fmt.Errorf("Artifact %d of %d (%s): %w", i+1, len(artifacts), artifacts[i].Value, err)
+		}
+		ret = append(ret, created...)
+	}
+	return ret, nil
+}
+
+// Replaces the artifact with a, returning it as stored by SOAR
+func (s *HTTPClient) UpdateArtifact(incID int, a *structures.Artifact) (*structures.Artifact, error) {
+	return orgJSON[structures.Artifact](s, "PUT", fmt.Sprintf("incidents/%d/artifacts/%d", incID, a.ID), a)
+}
+
+func (s *HTTPClient) DeleteArtifact(incID, id int) error {
+	return s.orgCall("DELETE", fmt.Sprintf("incidents/%d/artifacts/%d", incID, id), nil)
+}
+
+// Artifact types of the organization, cached after the first call (see ResetCache)
+func (s *HTTPClient) GetArtifactTypes() ([]structures.ArtifactType, error) {
+	types, err := cachedJSON[[]structures.ArtifactType](s, "artifact_types")
+	if err != nil {
+		return nil, err
+	}
+	return *types, nil
+}
+
+// Artifact type ID by its name ("IP Address"), programmatic name ("DNS Name", "dns_name") or ID
+func (s *HTTPClient) ArtifactTypeID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	types, err := s.GetArtifactTypes()
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range types {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.ProgrammaticName, name) {
+			return t.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown artifact type %q", name)
+}
+
+// Artifact of the named type, e.g. NewArtifact("DNS Name", "example.com")
+func (s *HTTPClient) NewArtifact(typeName, value string) (*structures.Artifact, error) {
+	id, err := s.ArtifactTypeID(typeName)
+	if err != nil {
+		return nil, err
+	}
+	return &structures.Artifact{Type: id, Value: value}, nil
+}
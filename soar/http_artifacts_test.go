@@ -0,0 +1,103 @@
+package soar
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestArtifacts(t *testing.T) {
+	stored := map[int]structures.Artifact{}
+	nextID := 1
+	client := restClient(t, func(req *http.Request) (int, any) {
+		path := orgPath(req)
+		switch {
+		case req.Method == "POST" && path == "incidents/2095/artifacts":
+			var a structures.Artifact
+			json.NewDecoder(req.Body).Decode(&a)
+			if a.Value == "bad" {
+				return 400, map[string]any{"message": "Invalid value"}
+			}
+			a.ID, a.IncID = nextID, 2095
+			nextID++
+			stored[a.ID] = a
+			return 200, []structures.Artifact{a}
+		case req.Method == "GET" && path == "incidents/2095/artifacts":
+			list := []structures.Artifact{}
+			for id := 1; id < nextID; id++ {
+				if a, ok := stored[id]; ok {
+					list = append(list, a)
+				}
+			}
+			return 200, list
+		case req.Method == "PUT" && path == "incidents/2095/artifacts/1":
+			var a structures.Artifact
+			json.NewDecoder(req.Body).Decode(&a)
+			stored[1] = a
+			return 200, a
+		case req.Method == "DELETE" && path == "incidents/2095/artifacts/2":
+			delete(stored, 2)
+			return 204, nil
+		}
+		return 404, nil
+	})
+
+	created, err := client.CreateArtifacts(2095, []structures.Artifact{
+		{Type: 1, Value: "10.0.0.1"},
+		{Type: 2, Value: "example.com"},
+		{Type: 2, Value: "bad"},
+	})
+	if len(created) != 2 || !IsStatus(err, 400) || !strings.Contains(err.Error(), "Artifact 3 of 3 (bad)") {
+		t.Fatalf("Expected partial bulk result, got %v, %v", created, err)
+	}
+	a := created[0]
+	a.Description = &structures.TextContent{Format: "text", Content: "Sender IP"}
+	if _, err := client.UpdateArtifact(2095, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteArtifact(2095, 2); err != nil {
+		t.Fatal(err)
+	}
+	list, err := client.GetArtifacts(2095)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Description.Content != "Sender IP" {
+		t.Errorf("Unexpected artifacts: %+v", list)
+	}
+}
+
+func TestArtifactTypes(t *testing.T) {
+	requests := 0
+	client := restClient(t, func(req *http.Request) (int, any) {
+		requests++
+		return 200, []structures.ArtifactType{
+			{ID: 1, Name: "IP Address", ProgrammaticName: "IP Address"},
+			{ID: 2, Name: "DNS Name", ProgrammaticName: "DNS Name"},
+			{ID: 13, Name: "Malware MD5 Hash", ProgrammaticName: "Malware MD5 Hash"},
+		}
+	})
+	client.cache = &definitionCache{entries: map[string]any{}}
+
+	a, err := client.NewArtifact("malware md5 hash", "d41d8cd98f00b204e9800998ecf8427e")
+	if err != nil || a.Type != 13 {
+		t.Errorf("Expected type 13, got %+v, %v", a, err)
+	}
+	if id, err := client.ArtifactTypeID("DNS Name"); err != nil || id != 2 {
+		t.Errorf("Expected type 2, got %d, %v", id, err)
+	}
+	if _, err := client.ArtifactTypeID("Unknown"); err == nil {
+		t.Error("Expected unknown artifact type error")
+	}
+	if requests != 1 {
+		t.Errorf("Expected artifact types to be cached, got %d requests", requests)
+	}
+	client.ResetCache()
+	client.ArtifactTypeID("DNS Name")
+	if requests != 2 {
+		t.Errorf("Expected artifact types to be fetched after reset, got %d requests", requests)
+	}
+}
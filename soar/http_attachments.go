@@ -0,0 +1,214 @@
+package soar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Incident or task the attachments belong to
+type AttachmentTarget struct {
+	path string
+}
+
+func IncidentAttachments(incID int) AttachmentTarget {
+	return AttachmentTarget{path: fmt.Sprintf("incidents/%d/attachments", incID)}
+}
+
+func TaskAttachments(taskID int) AttachmentTarget {
+	return AttachmentTarget{path: fmt.Sprintf("tasks/%d/attachments", taskID)}
+}
+
+// File to upload, Reader is read once and streamed to SOAR
+type File struct {
+	Name   string
+	Reader io.Reader
+	// Detected from the name extension or the first 512 bytes if empty
+	ContentType string
+}
+
+// Properties of an uploaded or downloaded file computed while streaming it
+type FileDigest struct {
+	Size        int64
+	SHA256      string
+	ContentType string
+}
+
+func (s *HTTPClient) GetAttachments(t AttachmentTarget) ([]structures.Attachment, error) {
+	list, err := orgJSON[[]structures.Attachment](s, "GET", t.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
+func (s *HTTPClient) DeleteAttachment(t AttachmentTarget, id int) error {
+	return s.orgCall("DELETE", fmt.Sprintf("%s/%d", t.path, id), nil)
+}
+
+// Streams the file to SOAR as a multipart upload without buffering it
+func (s *HTTPClient) UploadAttachment(t AttachmentTarget, f File) (*structures.Attachment, *FileDigest, error) {
+	resp, digest, err := s.upload(t.path, f, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := decodeResponse[structures.Attachment](resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, digest, nil
+}
+
+// Creates a file artifact (e.g. "Malware Sample", "Email Attachment") with the file streamed as its attachment
+func (s *HTTPClient) CreateFileArtifact(incID int, a *structures.Artifact, f File) (*structures.Artifact, *FileDigest, error) {
+	meta, err := json.Marshal(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	extra := func(mw *multipart.Writer) error {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {`form-data; name="artifact"`},
+			"Content-Type":        {"application/json"},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(meta)
+		return err
+	}
+	resp, digest, err := s.upload(fmt.Sprintf("incidents/%d/artifacts/files", incID), f, extra)
+	if err != nil {
+		return nil, nil, err
+	}
+	artifact, err := decodeResponse[structures.Artifact](resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return artifact, digest, nil
+}
+
+// Streams the attachment contents to w
+func (s *HTTPClient) DownloadAttachment(t AttachmentTarget, id int, w io.Writer) (*FileDigest, error) {
+	return s.download(fmt.Sprintf("%s/%d/contents", t.path, id), w)
+}
+
+// Streams the file of a file artifact to w
+func (s *HTTPClient) DownloadArtifactFile(incID, artifactID int, w io.Writer) (*FileDigest, error) {
+	return s.download(fmt.Sprintf("incidents/%d/artifacts/%d/contents", incID, artifactID), w)
+}
+
+// Client for file transfers, limited by FileTimeout instead of the request timeout
+func (s *HTTPClient) fileClient() *HTTPClient {
+	c := *s
+	c.Client.Timeout = s.FileTimeout
+	return &c
+}
+
+// Sends f as the "file" part after the parts written by extra, computing its digest on the way
+func (s *HTTPClient) upload(url string, f File, extra func(*multipart.Writer) error) (*http.Response, *FileDigest, error) {
+	contentType, r, err := detectContentType(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	counter := &digestWriter{hash: sha256.New()}
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	written := make(chan error, 1)
+	go func() {
+		err := writeMultipart(mw, extra, f.Name, contentType, io.TeeReader(r, counter))
+		pw.CloseWithError(err)
+		written <- err
+	}()
+	resp, err := s.fileClient().send("POST", fmt.Sprintf("orgs/%d/%s", s.Org.ID, url), pr, mw.FormDataContentType())
+	// unblocks the writer if the request failed before reading the whole body
+	pr.Close()
+	if werr := <-written; werr != nil && !errors.Is(werr, io.ErrClosedPipe) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, nil, fmt.Errorf("Upload of %s failed: %w", f.Name, werr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, counter.digest(contentType), nil
+}
+
+func writeMultipart(mw *multipart.Writer, extra func(*multipart.Writer) error, name, contentType string, r io.Reader) error {
+	if extra != nil {
+		if err := extra(mw); err != nil {
+			return err
+		}
+	}
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": name})},
+		"Content-Type":        {contentType},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+func (s *HTTPClient) download(url string, w io.Writer) (*FileDigest, error) {
+	resp, err := s.fileClient().OrgRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	counter := &digestWriter{hash: sha256.New()}
+	if _, err := io.Copy(io.MultiWriter(w, counter), resp.Body); err != nil {
+		return nil, err
+	}
+	return counter.digest(resp.Header.Get("Content-Type")), nil
+}
+
+// Content type of the file and a reader yielding its whole content
+func detectContentType(f File) (string, io.Reader, error) {
+	if f.ContentType != "" {
+		return f.ContentType, f.Reader, nil
+	}
+	if ct := mime.TypeByExtension(strings.ToLower(filepath.Ext(f.Name))); ct != "" {
+		return ct, f.Reader, nil
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f.Reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	return http.DetectContentType(head[:n]), io.MultiReader(bytes.NewReader(head[:n]), f.Reader), nil
+}
+
+// Counts and hashes bytes written to it
+type digestWriter struct {
+	hash hash.Hash
+	size int64
+}
+
+func (d *digestWriter) Write(p []byte) (int, error) {
+	d.size += int64(len(p))
+	return d.hash.Write(p)
+}
+
+func (d *digestWriter) digest(contentType string) *FileDigest {
+	return &FileDigest{Size: d.size, SHA256: hex.EncodeToString(d.hash.Sum(nil)), ContentType: contentType}
+}
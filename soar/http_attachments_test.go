@@ -0,0 +1,117 @@
+package soar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestUploadAttachment(t *testing.T) {
+	content := []byte("%PDF-1.7\n" + strings.Repeat("report ", 10000))
+	sum := sha256.Sum256(content)
+	client := restClient(t, func(req *http.Request) (int, any) {
+		if req.Method != "POST" || orgPath(req) != "tasks/17/attachments" {
+			t.Fatalf("Unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		file, header, err := req.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(file)
+		if !bytes.Equal(data, content) || header.Filename != "report" {
+			t.Errorf("Unexpected upload %q of %d bytes", header.Filename, len(data))
+		}
+		return 200, structures.Attachment{ID: 5, Name: header.Filename, ContentType: header.Header.Get("Content-Type")}
+	})
+
+	// no extension, the content type is sniffed from the content
+	attachment, digest, err := client.UploadAttachment(TaskAttachments(17), File{Name: "report", Reader: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.ID != 5 || attachment.ContentType != "application/pdf" {
+		t.Errorf("Unexpected attachment: %+v", attachment)
+	}
+	if digest.SHA256 != hex.EncodeToString(sum[:]) || digest.Size != int64(len(content)) || digest.ContentType != "application/pdf" {
+		t.Errorf("Unexpected digest: %+v", digest)
+	}
+}
+
+func TestUploadAttachmentError(t *testing.T) {
+	client := restClient(t, func(req *http.Request) (int, any) {
+		return 413, map[string]any{"message": "Attachment is too large"}
+	})
+	_, _, err := client.UploadAttachment(IncidentAttachments(2095), File{Name: "big.bin", Reader: strings.NewReader("data")})
+	if !IsStatus(err, 413) {
+		t.Errorf("Expected 413 error, got %v", err)
+	}
+}
+
+func TestCreateFileArtifact(t *testing.T) {
+	client := restClient(t, func(req *http.Request) (int, any) {
+		if req.Method != "POST" || orgPath(req) != "incidents/2095/artifacts/files" {
+			t.Fatalf("Unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		var a structures.Artifact
+		if err := json.Unmarshal([]byte(req.FormValue("artifact")), &a); err != nil {
+			t.Fatal(err)
+		}
+		_, header, err := req.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.ID = 9
+		a.Attachment = &structures.Attachment{Name: header.Filename, ContentType: header.Header.Get("Content-Type")}
+		return 200, a
+	})
+	a, digest, err := client.CreateFileArtifact(2095, &structures.Artifact{Type: 23, Value: "sample.exe"},
+		File{Name: "sample.exe", Reader: strings.NewReader("MZ")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != 9 || a.Type != 23 || a.Attachment.Name != "sample.exe" {
+		t.Errorf("Unexpected artifact: %+v", a)
+	}
+	if digest.Size != 2 {
+		t.Errorf("Unexpected digest: %+v", digest)
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	content := []byte("sandbox report")
+	sum := sha256.Sum256(content)
+	client := restClient(t, func(req *http.Request) (int, any) {
+		switch orgPath(req) {
+		case "incidents/2095/attachments/5/contents", "incidents/2095/artifacts/9/contents":
+			return 200, content
+		}
+		return 404, nil
+	})
+	var buf bytes.Buffer
+	digest, err := client.DownloadAttachment(IncidentAttachments(2095), 5, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "sandbox report" || digest.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected download %q, %+v", buf.String(), digest)
+	}
+	if _, err := client.DownloadArtifactFile(2095, 9, io.Discard); err != nil {
+		t.Error(err)
+	}
+	if _, err := client.DownloadAttachment(TaskAttachments(1), 1, io.Discard); !IsNotFound(err) {
+		t.Errorf("Expected 404, got %v", err)
+	}
+}
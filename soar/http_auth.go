@@ -0,0 +1,146 @@
+package soar
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Adds credentials to REST requests of HTTPClient
+type Authenticator interface {
+	// Sets the credentials on the request, logging in first if needed
+	Authenticate(s *HTTPClient, req *http.Request) error
+	// Drops credentials rejected by SOAR for the request, returns whether repeating it may succeed
+	Invalidate(req *http.Request) bool
+}
+
+// API key Basic authentication, the only one supported by the STOMP listener
+type APIKeyAuth struct {
+	KeyId     string
+	KeySecret string
+}
+
+func (a APIKeyAuth) Authenticate(s *HTTPClient, req *http.Request) error {
+	auth := base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "%s:%s", a.KeyId, a.KeySecret))
+	req.Header.Set("Authorization", "Basic "+auth)
+	return nil
+}
+
+func (a APIKeyAuth) Invalidate(req *http.Request) bool { return false }
+
+// Warning period before the password expiration used by PasswordAuth unless overridden
+const DefaultPasswordExpiryWarning = 14 * 24 * time.Hour
+
+// User session authentication: logs in via POST /rest/session, sends the session cookie
+// along with X-sess-id CSRF header and logs in again once the session expires
+type PasswordAuth struct {
+	Email    string
+	Password string
+	// Password expiration closer than this is logged as a warning after login
+	ExpiryWarning time.Duration
+	// Called after each login with the password expiration time, if SOAR reports one
+	OnPasswordExpiry func(expires time.Time)
+
+	mu      sync.Mutex
+	csrf    string
+	cookies []*http.Cookie
+	expires time.Time
+}
+
+func NewPasswordAuth(email, password string) *PasswordAuth {
+	return &PasswordAuth{
+		Email:         email,
+		Password:      password,
+		ExpiryWarning: DefaultPasswordExpiryWarning,
+	}
+}
+
+func (a *PasswordAuth) Authenticate(s *HTTPClient, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.csrf == "" {
+		if err := a.login(s, req); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("X-sess-id", a.csrf)
+	for _, c := range a.cookies {
+		req.AddCookie(c)
+	}
+	return nil
+}
+
+func (a *PasswordAuth) Invalidate(req *http.Request) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// a concurrent request may have already logged in again
+	if a.csrf == req.Header.Get("X-sess-id") {
+		a.csrf, a.cookies = "", nil
+	}
+	return true
+}
+
+// Password expiration time reported on the last login, zero if unknown
+func (a *PasswordAuth) PasswordExpires() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.expires
+}
+
+type sessionRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (a *PasswordAuth) login(s *HTTPClient, orig *http.Request) error {
+	body, err := json.Marshal(sessionRequest{Email: a.Email, Password: a.Password})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(orig.Context(), "POST", s.baseURL()+"session", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	cookies := resp.Cookies()
+	session, err := decodeResponse[structures.SessionResponseJson](resp)
+	if err != nil {
+		return fmt.Errorf("SOAR login failed for %s: %w", a.Email, err)
+	}
+	if session.CSRFToken == "" {
+		return fmt.Errorf("SOAR login failed for %s: no CSRF token in session response", a.Email)
+	}
+	a.csrf, a.cookies = session.CSRFToken, cookies
+	a.expires = time.Time{}
+	if session.PasswordExpirationDate > 0 {
+		a.expires = time.UnixMilli(session.PasswordExpirationDate)
+		a.warnExpiry()
+	}
+	return nil
+}
+
+func (a *PasswordAuth) warnExpiry() {
+	if a.OnPasswordExpiry != nil {
+		a.OnPasswordExpiry(a.expires)
+	}
+	if left := time.Until(a.expires); left < a.ExpiryWarning {
+		slog.Warn("SOAR password expires soon",
+			slog.String("email", a.Email),
+			slog.Time("expires", a.expires),
+			slog.Duration("left", left.Round(time.Minute)))
+	}
+}
@@ -0,0 +1,135 @@
+package soar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// SOAR session endpoint handing out a new CSRF token per login, expireAfter requests are served per session
+func sessionServer(t *testing.T, expireAfter int, logins *int) *mockRoundTripper {
+	served := 0
+	return &mockRoundTripper{
+		roundTripFunc: func(req *http.Request) *http.Response {
+			resp := &http.Response{StatusCode: 200, Header: make(http.Header), Request: req}
+			if req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/rest/session") {
+				var creds sessionRequest
+				json.NewDecoder(req.Body).Decode(&creds)
+				if creds.Password != "secret" {
+					resp.StatusCode = 401
+					resp.Body = io.NopCloser(strings.NewReader(`{"success": false, "message": "Bad credentials"}`))
+					return resp
+				}
+				*logins++
+				served = 0
+				token := fmt.Sprintf("token-%d", *logins)
+				resp.Header.Add("Set-Cookie", "JSESSIONID=session-"+token)
+				body, _ := json.Marshal(structures.SessionResponseJson{
+					CSRFToken:              token,
+					PasswordExpirationDate: time.Now().Add(48 * time.Hour).UnixMilli(),
+				})
+				resp.Body = io.NopCloser(strings.NewReader(string(body)))
+				return resp
+			}
+			token := fmt.Sprintf("token-%d", *logins)
+			cookie, err := req.Cookie("JSESSIONID")
+			if req.Header.Get("X-sess-id") != token || err != nil || cookie.Value != "session-"+token {
+				t.Errorf("Unexpected session headers: %v", req.Header)
+			}
+			if req.Header.Get("Authorization") != "" {
+				t.Error("Expected no API key auth in user session")
+			}
+			if served++; served > expireAfter {
+				resp.StatusCode = 401
+				resp.Body = io.NopCloser(strings.NewReader(`{"success": false, "message": "Session expired"}`))
+				return resp
+			}
+			resp.Body = io.NopCloser(strings.NewReader(`{"orgs": [{"id": 201}]}`))
+			return resp
+		},
+	}
+}
+
+func TestPasswordAuth(t *testing.T) {
+	logins := 0
+	var expires time.Time
+	auth := NewPasswordAuth("admin@example.com", "secret")
+	auth.OnPasswordExpiry = func(e time.Time) { expires = e }
+	client := &HTTPClient{
+		Hostname: "test.local",
+		Ctx:      context.Background(),
+		Auth:     auth,
+		Client:   http.Client{Transport: sessionServer(t, 2, &logins)},
+	}
+	for range 5 {
+		if _, err := client.GetOrg(); err != nil {
+			t.Fatalf("Expected transparent re-login, got %v", err)
+		}
+	}
+	if logins != 3 {
+		t.Errorf("Expected 3 logins for 5 requests with sessions of 2, got %d", logins)
+	}
+	if time.Until(expires) < 47*time.Hour || !auth.PasswordExpires().Equal(expires) {
+		t.Errorf("Expected password expiration in 48h, got %v", expires)
+	}
+}
+
+func TestPasswordAuthFailure(t *testing.T) {
+	logins := 0
+	client := &HTTPClient{
+		Hostname: "test.local",
+		Ctx:      context.Background(),
+		Auth:     NewPasswordAuth("admin@example.com", "wrong"),
+		Client:   http.Client{Transport: sessionServer(t, 1, &logins)},
+	}
+	_, err := client.GetOrg()
+	if !errors.Is(err, ErrUnauthorized) || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := NewStompListener(client); !errors.Is(err, ErrAPIKeyRequired) {
+		t.Errorf("Expected ErrAPIKeyRequired for user session, got %v", err)
+	}
+}
+
+func TestPasswordAuthStompAccessCheck(t *testing.T) {
+	client := &HTTPClient{
+		Hostname:  "test.local",
+		KeyId:     "key-id",
+		KeySecret: "key-secret",
+		Ctx:       context.Background(),
+		Org:       &structures.Org{ID: 201},
+		// user session, no API key handle
+		Session: &structures.SessionResponseJson{},
+		Auth:    NewPasswordAuth("admin@example.com", "secret"),
+		Client: http.Client{Transport: &mockRoundTripper{
+			roundTripFunc: func(req *http.Request) *http.Response {
+				resp := &http.Response{StatusCode: 200, Header: make(http.Header), Request: req}
+				id, secret, basic := req.BasicAuth()
+				switch {
+				case req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/rest/session"):
+					resp.Body = io.NopCloser(strings.NewReader(`{"csrf_token": "token"}`))
+				case req.URL.Path == "/rest/session" && basic && id == "key-id" && secret == "key-secret":
+					resp.Body = io.NopCloser(strings.NewReader(`{"api_key_handle": 42, "orgs": [{"id": 201}]}`))
+				case strings.HasSuffix(req.URL.Path, "/message_destinations/fn"):
+					resp.Body = io.NopCloser(strings.NewReader(`{"api_keys": [42]}`))
+				default:
+					t.Errorf("Unexpected request %s %s", req.Method, req.URL.Path)
+					resp.StatusCode = 404
+					resp.Body = io.NopCloser(strings.NewReader(`{}`))
+				}
+				return resp
+			},
+		}},
+	}
+	if _, err := NewStompListener(client, Stomp.MessageDestination("fn")); err != nil {
+		t.Errorf("Expected access check with the API key handle, got %v", err)
+	}
+}
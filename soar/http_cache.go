@@ -0,0 +1,42 @@
+package soar
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Definitions of an organization (artifact types, phases, ...) that rarely change,
+// kept per org and shared with clients derived by ForOrg
+type definitionCache struct {
+	mu      sync.Mutex
+	entries map[string]any
+}
+
+// GET response of the org path decoded into T, fetched once per organization unless the client has no cache
+func cachedJSON[T any](s *HTTPClient, url string) (*T, error) {
+	if s.cache == nil {
+		return orgJSON[T](s, "GET", url, nil)
+	}
+	key := fmt.Sprintf("%d/%s", s.Org.ID, url)
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	if v, ok := s.cache.entries[key].(*T); ok {
+		return v, nil
+	}
+	v, err := orgJSON[T](s, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.entries[key] = v
+	return v, nil
+}
+
+// Drops cached definitions, e.g. after artifact types or phases were changed in SOAR
+func (s *HTTPClient) ResetCache() {
+	if s.cache == nil {
+		return
+	}
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	clear(s.cache.entries)
+}
@@ -0,0 +1,183 @@
+package soar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// No data table row has the key value
+var ErrRowNotFound = errors.New("data table row not found")
+
+func tablePath(incID int, table string) string {
+	return fmt.Sprintf("incidents/%d/table_data/%s", incID, table)
+}
+
+// Rows of the incident data table
+func (s *HTTPClient) GetDataTableRows(incID int, table string) ([]structures.DataTableRow, error) {
+	dt, err := orgJSON[structures.DataTable](s, "GET", tablePath(incID, table), nil)
+	if err != nil {
+		return nil, err
+	}
+	return dt.Rows, nil
+}
+
+func (s *HTTPClient) AddDataTableRow(incID int, table string, cells map[string]structures.Cell) (*structures.DataTableRow, error) {
+	return orgJSON[structures.DataTableRow](s, "POST", tablePath(incID, table)+"/row_data",
+		&structures.DataTableRow{Cells: cells})
+}
+
+// Sets the given cells of the row, other cells keep their values
+func (s *HTTPClient) UpdateDataTableRow(incID int, table string, rowID int, cells map[string]structures.Cell) (*structures.DataTableRow, error) {
+	return orgJSON[structures.DataTableRow](s, "PUT", fmt.Sprintf("%s/row_data/%d", tablePath(incID, table), rowID),
+		&structures.DataTableRow{ID: rowID, Cells: cells})
+}
+
+// Updates every row whose key column equals key, ErrRowNotFound if there are none
+func (s *HTTPClient) UpdateDataTableRowsByKey(incID int, table, keyColumn string, key any, cells map[string]structures.Cell) ([]structures.DataTableRow, error) {
+	rows, err := s.GetDataTableRows(incID, table)
+	if err != nil {
+		return nil, err
+	}
+	want, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	var updated []structures.DataTableRow
+	for _, row := range rows {
+		got, err := json.Marshal(row.Cells[keyColumn].Value)
+		if err != nil || !bytes.Equal(got, want) {
+			continue
+		}
+		r, err := s.UpdateDataTableRow(incID, table, row.ID, cells)
+		if err != nil {
+			return updated, err
+		}
+		updated = append(updated, *r)
+	}
+	if len(updated) == 0 {
+		return nil, fmt.Errorf("%w: %s.%s = %s", ErrRowNotFound, table, keyColumn, want)
+	}
+	return updated, nil
+}
+
+func (s *HTTPClient) DeleteDataTableRow(incID int, table string, rowID int) error {
+	return s.orgCall("DELETE", fmt.Sprintf("%s/row_data/%d", tablePath(incID, table), rowID), nil)
+}
+
+// Column definitions of the data table, cached after the first call (see ResetCache)
+func (s *HTTPClient) GetDataTableColumns(table string) ([]structures.FieldDefinition, error) {
+	columns, err := cachedJSON[[]structures.FieldDefinition](s, fmt.Sprintf("types/%s/fields", table))
+	if err != nil {
+		return nil, err
+	}
+	return *columns, nil
+}
+
+// Rows of the data table mapped to T, see MarshalCells for the struct tags
+func GetRows[T any](s *HTTPClient, incID int, table string) ([]T, error) {
+	columns, err := s.GetDataTableColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.GetDataTableRows(incID, table)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]T, len(rows))
+	for i := range rows {
+		if err := UnmarshalRow(&rows[i], &ret[i], columns); err != nil {
+			return nil, fmt.Errorf("Row %d: %w", rows[i].ID, err)
+		}
+	}
+	return ret, nil
+}
+
+// Adds v as a new row, its rowid field is set to the ID of the row
+func AddRow[T any](s *HTTPClient, incID int, table string, v *T) error {
+	cells, err := rowCells(s, table, v)
+	if err != nil {
+		return err
+	}
+	row, err := s.AddDataTableRow(incID, table, cells)
+	if err != nil {
+		return err
+	}
+	setRowID(v, row.ID)
+	return nil
+}
+
+// Saves v to the row given by its rowid field
+func UpdateRow[T any](s *HTTPClient, incID int, table string, v *T) error {
+	rowID, ok := getRowID(v)
+	if !ok || rowID == 0 {
+		return fmt.Errorf("%T has no row ID, tag an int field with `soar:\",rowid\"`", v)
+	}
+	cells, err := rowCells(s, table, v)
+	if err != nil {
+		return err
+	}
+	_, err = s.UpdateDataTableRow(incID, table, rowID, cells)
+	return err
+}
+
+// Saves v to the rows with the same value of the key column, adding a row if there are none
+func UpsertRow[T any](s *HTTPClient, incID int, table, keyColumn string, v *T) error {
+	cells, err := rowCells(s, table, v)
+	if err != nil {
+		return err
+	}
+	key, ok := cells[keyColumn]
+	if !ok {
+		return fmt.Errorf("%T has no field for the key column %s", v, keyColumn)
+	}
+	rows, err := s.UpdateDataTableRowsByKey(incID, table, keyColumn, key.Value, cells)
+	if errors.Is(err, ErrRowNotFound) {
+		return AddRow(s, incID, table, v)
+	}
+	if err != nil {
+		return err
+	}
+	setRowID(v, rows[0].ID)
+	return nil
+}
+
+func rowCells(s *HTTPClient, table string, v any) (map[string]structures.Cell, error) {
+	columns, err := s.GetDataTableColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalCells(v, columns)
+}
+
+// Field tagged `soar:",rowid"` of the struct pointed to by v
+func rowIDField(v any) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := range rv.NumField() {
+		if tag, ok := parseColumnTag(rv.Type().Field(i)); ok && tag.rowID && rv.Field(i).CanInt() {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func getRowID(v any) (int, bool) {
+	f, ok := rowIDField(v)
+	if !ok {
+		return 0, false
+	}
+	return int(f.Int()), true
+}
+
+func setRowID(v any, id int) {
+	if f, ok := rowIDField(v); ok {
+		f.SetInt(int64(id))
+	}
+}
@@ -0,0 +1,115 @@
+package soar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestDataTableRows(t *testing.T) {
+	rows := map[int]structures.DataTableRow{}
+	nextID := 1
+	var requests []string
+	client := restClient(t, func(req *http.Request) (int, any) {
+		path := orgPath(req)
+		requests = append(requests, req.Method+" "+path)
+		var body structures.DataTableRow
+		if req.Body != nil {
+			json.NewDecoder(req.Body).Decode(&body)
+		}
+		switch {
+		case path == "types/hits/fields":
+			return 200, testColumns
+		case req.Method == "GET" && path == "incidents/2095/table_data/hits":
+			dt := structures.DataTable{ID: 1000}
+			for id := 1; id < nextID; id++ {
+				if row, ok := rows[id]; ok {
+					// values as decoded from SOAR JSON
+					data, _ := json.Marshal(row)
+					json.Unmarshal(data, &row)
+					dt.Rows = append(dt.Rows, row)
+				}
+			}
+			return 200, dt
+		case req.Method == "POST" && path == "incidents/2095/table_data/hits/row_data":
+			body.ID = nextID
+			rows[nextID] = body
+			nextID++
+			return 200, body
+		case req.Method == "PUT":
+			var id int
+			fmt.Sscanf(path, "incidents/2095/table_data/hits/row_data/%d", &id)
+			row := rows[id]
+			for name, cell := range body.Cells {
+				row.Cells[name] = cell
+			}
+			rows[id] = row
+			return 200, row
+		case req.Method == "DELETE":
+			var id int
+			fmt.Sscanf(path, "incidents/2095/table_data/hits/row_data/%d", &id)
+			delete(rows, id)
+			return 204, nil
+		}
+		return 404, nil
+	})
+	client.cache = &definitionCache{entries: map[string]any{}}
+
+	first := testHit{IP: "10.0.0.1", Verdict: "Clean"}
+	if err := AddRow(client, 2095, "hits", &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.RowID != 1 {
+		t.Errorf("Expected row ID to be set, got %d", first.RowID)
+	}
+	second := testHit{IP: "10.0.0.2", Verdict: "Clean"}
+	if err := UpsertRow(client, 2095, "hits", "ip_address", &second); err != nil || second.RowID != 2 {
+		t.Fatalf("Expected a new row 2, got %d, %v", second.RowID, err)
+	}
+
+	// by key column
+	update := testHit{IP: "10.0.0.2", Verdict: "Malicious", Blocked: true}
+	if err := UpsertRow(client, 2095, "hits", "ip_address", &update); err != nil || update.RowID != 2 {
+		t.Fatalf("Expected row 2 to be updated, got %d, %v", update.RowID, err)
+	}
+	// by row ID
+	first.Tags = []string{"Tor"}
+	if err := UpdateRow(client, 2095, "hits", &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateRow(client, 2095, "hits", &testHit{}); err == nil {
+		t.Error("Expected missing row ID error")
+	}
+	_, err := client.UpdateDataTableRowsByKey(2095, "hits", "ip_address", "10.9.9.9", nil)
+	if !errors.Is(err, ErrRowNotFound) {
+		t.Errorf("Expected ErrRowNotFound, got %v", err)
+	}
+
+	hits, err := GetRows[testHit](client, 2095, "hits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].Tags[0] != "Tor" || hits[1].Verdict != "Malicious" || !hits[1].Blocked {
+		t.Errorf("Unexpected rows: %+v", hits)
+	}
+
+	if err := client.DeleteDataTableRow(2095, "hits", 1); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := GetRows[testHit](client, 2095, "hits"); len(hits) != 1 || hits[0].RowID != 2 {
+		t.Errorf("Expected only row 2 left, got %+v", hits)
+	}
+	columnRequests := 0
+	for _, r := range requests {
+		if r == "GET types/hits/fields" {
+			columnRequests++
+		}
+	}
+	if columnRequests != 1 {
+		t.Errorf("Expected columns to be cached, got %d requests", columnRequests)
+	}
+}
@@ -0,0 +1,178 @@
+package soar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func (s *HTTPClient) GetIncident(id int) (*structures.Incident, error) {
+	return orgJSON[structures.Incident](s, "GET", fmt.Sprintf("incidents/%d", id), nil)
+}
+
+// Creates the incident, returning it as stored by SOAR (with ID and defaults filled in)
+func (s *HTTPClient) CreateIncident(inc *structures.Incident) (*structures.Incident, error) {
+	return orgJSON[structures.Incident](s, "POST", "incidents", inc)
+}
+
+func (s *HTTPClient) DeleteIncident(id int) error {
+	return s.orgCall("DELETE", fmt.Sprintf("incidents/%d", id), nil)
+}
+
+// Changes fields (by API name, custom ones included) of the incident as it was read by GetIncident.
+// The values read are sent as old values, so concurrent changes of the same fields fail with ConflictError
+// instead of being overwritten. Fields absent from the read incident (or from one not read from SOAR) have no old value
+func (s *HTTPClient) UpdateIncident(inc *structures.Incident, fields map[string]any) error {
+	old, err := incidentValues(inc)
+	if err != nil {
+		return err
+	}
+	patch := &structures.Patch{Version: inc.Version}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		change := structures.FieldChange{
+			Field:    structures.FieldName{Name: name},
+			NewValue: &structures.FieldValueDTO{Object: fields[name]},
+		}
+		if v, ok := old[name]; ok {
+			change.OldValue = &structures.FieldValueDTO{Object: v}
+		}
+		patch.Changes = append(patch.Changes, change)
+	}
+	return s.PatchIncident(inc.ID, patch)
+}
+
+// Applies raw field changes to the incident
+func (s *HTTPClient) PatchIncident(id int, patch *structures.Patch) error {
+	status, err := orgJSON[structures.PatchStatus](s, "PATCH", fmt.Sprintf("incidents/%d", id), patch)
+	if err != nil {
+		return err
+	}
+	if !status.Success {
+		return &ConflictError{Title: status.Title, Message: status.Message, Fields: status.FieldFailures}
+	}
+	return nil
+}
+
+// Incident fields defined in the organization
+func (s *HTTPClient) GetIncidentFields() ([]structures.FieldDefinition, error) {
+	fields, err := orgJSON[[]structures.FieldDefinition](s, "GET", "types/incident/fields", nil)
+	if err != nil {
+		return nil, err
+	}
+	return *fields, nil
+}
+
+// Closes the incident with the resolution (label or ID) and summary. Values of other fields
+// required on close may be given in fields, the ones still empty fail with ErrMissingCloseFields
+func (s *HTTPClient) CloseIncident(id int, resolution, summary string, fields map[string]any) error {
+	inc, err := s.GetIncident(id)
+	if err != nil {
+		return err
+	}
+	defs, err := s.GetIncidentFields()
+	if err != nil {
+		return err
+	}
+	changes := map[string]any{
+		"plan_status":        structures.PlanStatusClosed,
+		"resolution_summary": summary,
+	}
+	maps.Copy(changes, fields)
+	if changes["resolution_id"], err = selectValue(defs, "resolution_id", resolution); err != nil {
+		return err
+	}
+	current, err := incidentValues(inc)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, def := range defs {
+		if def.Required != "close" && def.Name != "resolution_id" && def.Name != "resolution_summary" {
+			continue
+		}
+		value, ok := changes[def.Name]
+		if !ok {
+			value = current[def.Name]
+		}
+		if isEmptyValue(value) {
+			missing = append(missing, def.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingCloseFields, strings.Join(missing, ", "))
+	}
+	return s.UpdateIncident(inc, changes)
+}
+
+// ID of a select field option by its label, or the value itself if it is a number
+func selectValue(defs []structures.FieldDefinition, field, value string) (int, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+	for _, def := range defs {
+		if def.Name != field {
+			continue
+		}
+		labels := make([]string, 0, len(def.Values))
+		for _, v := range def.Values {
+			if strings.EqualFold(v.Label, value) {
+				return v.Value, nil
+			}
+			labels = append(labels, v.Label)
+		}
+		return 0, fmt.Errorf("Unknown %s value %q, available: %s", field, value, strings.Join(labels, ", "))
+	}
+	return 0, fmt.Errorf("Unknown field %s", field)
+}
+
+// Incident fields by API name as SOAR returned them, custom fields from properties included
+func incidentValues(inc *structures.Incident) (map[string]any, error) {
+	values := map[string]any{}
+	if len(inc.Raw) == 0 {
+		return values, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(inc.Raw))
+	// keeps numbers such as dates exactly as received
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nil, err
+	}
+	if props, ok := values["properties"].(map[string]any); ok {
+		maps.Copy(values, props)
+	}
+	delete(values, "properties")
+	return values, nil
+}
+
+// Whether a field required on close has no value, false and 0 are values
+func isEmptyValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// Incidents matching the query (all incidents if nil), fetched lazily page by page:
+//
+//	for inc, err := range client.Incidents(soar.NewQuery().Equals("plan_status", "A")) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (s *HTTPClient) Incidents(q *Query) iter.Seq2[structures.Incident, error] {
+	return queryPaged[structures.Incident](s, "incidents/query_paged?return_level=normal", q)
+}
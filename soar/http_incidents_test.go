@@ -0,0 +1,165 @@
+package soar
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestIncidentCRUD(t *testing.T) {
+	client := restClient(t, func(req *http.Request) (int, any) {
+		switch req.Method + " " + orgPath(req) {
+		case "GET incidents/2095":
+			return 200, json.RawMessage(`{"id": 2095, "name": "Phishing", "description": "<div>Reported</div>",
+				"vers": 4, "properties": {"source_ip": "10.0.0.1"}}`)
+		case "POST incidents":
+			if req.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("Expected JSON body, got %q", req.Header.Get("Content-Type"))
+			}
+			var inc structures.Incident
+			json.NewDecoder(req.Body).Decode(&inc)
+			inc.ID = 2096
+			return 200, inc
+		case "DELETE incidents/2095":
+			return 204, nil
+		}
+		return 404, map[string]any{"message": "not found"}
+	})
+
+	inc, err := client.GetIncident(2095)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inc.Description.Content != "<div>Reported</div>" || inc.Properties["source_ip"] != "10.0.0.1" || inc.Version != 4 {
+		t.Errorf("Unexpected incident: %+v", inc)
+	}
+	created, err := client.CreateIncident(&structures.Incident{
+		Name:        "New",
+		Description: &structures.TextContent{Format: "text", Content: "Created from Go"},
+	})
+	if err != nil || created.ID != 2096 || created.Description.Content != "Created from Go" {
+		t.Errorf("Unexpected created incident %+v, %v", created, err)
+	}
+	if err := client.DeleteIncident(2095); err != nil {
+		t.Errorf("Unexpected delete error: %v", err)
+	}
+	if _, err := client.GetIncident(1); !IsNotFound(err) {
+		t.Errorf("Expected 404, got %v", err)
+	}
+}
+
+func TestUpdateIncident(t *testing.T) {
+	var patch structures.Patch
+	status := structures.PatchStatus{Success: true}
+	client := restClient(t, func(req *http.Request) (int, any) {
+		if req.Method != "PATCH" || orgPath(req) != "incidents/2095" {
+			t.Fatalf("Unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		patch = structures.Patch{}
+		json.NewDecoder(req.Body).Decode(&patch)
+		return 200, status
+	})
+	inc := &structures.Incident{}
+	err := json.Unmarshal([]byte(`{"id": 2095, "name": "Phishing", "vers": 4, "confirmed": false, "severity_code": 0,
+		"negative_pr_likely": true, "properties": {"source_ip": "10.0.0.1"}}`), inc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.UpdateIncident(inc, map[string]any{"name": "Phishing campaign", "source_ip": "10.0.0.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch.Version != 4 || len(patch.Changes) != 2 {
+		t.Fatalf("Unexpected patch: %+v", patch)
+	}
+	name, ip := patch.Changes[0], patch.Changes[1]
+	if name.Field.Name != "name" || name.OldValue.Object != "Phishing" || name.NewValue.Object != "Phishing campaign" {
+		t.Errorf("Unexpected name change: %+v", name)
+	}
+	if ip.Field.Name != "source_ip" || ip.OldValue.Object != "10.0.0.1" || ip.NewValue.Object != "10.0.0.2" {
+		t.Errorf("Unexpected custom field change: %+v", ip)
+	}
+
+	// zero values and fields the struct does not model keep their old values, unknown fields have none
+	err = client.UpdateIncident(inc, map[string]any{
+		"confirmed": true, "severity_code": 5, "negative_pr_likely": false, "not_read": "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := map[string]*structures.FieldValueDTO{}
+	for _, c := range patch.Changes {
+		old[c.Field.Name] = c.OldValue
+	}
+	if old["confirmed"] == nil || old["confirmed"].Object != false {
+		t.Errorf("Expected old value false, got %+v", old["confirmed"])
+	}
+	if old["severity_code"] == nil || old["severity_code"].Object != float64(0) {
+		t.Errorf("Expected old value 0, got %+v", old["severity_code"])
+	}
+	if old["negative_pr_likely"] == nil || old["negative_pr_likely"].Object != true {
+		t.Errorf("Expected old value of unmodeled field, got %+v", old["negative_pr_likely"])
+	}
+	if old["not_read"] != nil {
+		t.Errorf("Expected no old value of a field not read, got %+v", old["not_read"])
+	}
+
+	status = structures.PatchStatus{FieldFailures: []structures.FieldFailure{{
+		Field:              structures.FieldName{Name: "name"},
+		YourOriginalValue:  "Phishing",
+		ActualCurrentValue: "Spam",
+	}}}
+	err = client.UpdateIncident(inc, map[string]any{"name": "Phishing campaign"})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !IsConflict(err) || conflict.Fields[0].ActualCurrentValue != "Spam" {
+		t.Errorf("Expected ConflictError, got %v", err)
+	}
+}
+
+func TestCloseIncident(t *testing.T) {
+	var patch *structures.Patch
+	client := restClient(t, func(req *http.Request) (int, any) {
+		switch req.Method + " " + orgPath(req) {
+		case "GET incidents/2095":
+			return 200, json.RawMessage(`{"id": 2095, "name": "Phishing", "vers": 4, "properties": {"reported_externally": false}}`)
+		case "GET types/incident/fields":
+			return 200, []structures.FieldDefinition{
+				{Name: "resolution_id", Required: "close", Values: []structures.FieldValue{
+					{Value: 7, Label: "Resolved"}, {Value: 8, Label: "Duplicate"},
+				}},
+				{Name: "resolution_summary", Required: "close"},
+				{Name: "root_cause", Required: "close", Prefix: "properties"},
+				{Name: "reported_externally", Required: "close", Prefix: "properties", InputType: "boolean"},
+				{Name: "description"},
+			}
+		case "PATCH incidents/2095":
+			patch = &structures.Patch{}
+			json.NewDecoder(req.Body).Decode(patch)
+			return 200, structures.PatchStatus{Success: true}
+		}
+		return 404, nil
+	})
+
+	err := client.CloseIncident(2095, "Resolved", "Blocked sender", nil)
+	if !errors.Is(err, ErrMissingCloseFields) || patch != nil || !strings.HasSuffix(err.Error(), ": root_cause") {
+		t.Errorf("Expected only root_cause missing (false is a value), got %v", err)
+	}
+	if err := client.CloseIncident(2095, "Fixed", "Blocked sender", nil); err == nil {
+		t.Error("Expected unknown resolution error")
+	}
+	err = client.CloseIncident(2095, "resolved", "Blocked sender", map[string]any{"root_cause": "User error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := map[string]any{}
+	for _, c := range patch.Changes {
+		changes[c.Field.Name] = c.NewValue.Object
+	}
+	if changes["plan_status"] != "C" || changes["resolution_id"] != float64(7) || changes["root_cause"] != "User error" {
+		t.Errorf("Unexpected close changes: %v", changes)
+	}
+}
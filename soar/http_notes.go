@@ -0,0 +1,57 @@
+package soar
+
+import (
+	"fmt"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Incident or task the notes belong to
+type NoteTarget struct {
+	path string
+}
+
+func IncidentNotes(incID int) NoteTarget {
+	return NoteTarget{path: fmt.Sprintf("incidents/%d/comments", incID)}
+}
+
+func TaskNotes(taskID int) NoteTarget {
+	return NoteTarget{path: fmt.Sprintf("tasks/%d/comments", taskID)}
+}
+
+// Plain text note content
+func TextNote(text string) structures.TextContent {
+	return structures.TextContent{Format: "text", Content: text}
+}
+
+// HTML note content, see HTMLBuilder for escaping of untrusted values
+func HTMLNote(html string) structures.TextContent {
+	return structures.TextContent{Format: "html", Content: html}
+}
+
+// Top-level notes of the target, replies are in Children
+func (s *HTTPClient) GetNotes(t NoteTarget) ([]structures.Note, error) {
+	notes, err := orgJSON[[]structures.Note](s, "GET", t.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return *notes, nil
+}
+
+func (s *HTTPClient) CreateNote(t NoteTarget, text structures.TextContent) (*structures.Note, error) {
+	return orgJSON[structures.Note](s, "POST", t.path, &structures.Note{Text: &text})
+}
+
+// Adds a note to the thread of parentID
+func (s *HTTPClient) ReplyNote(t NoteTarget, parentID int, text structures.TextContent) (*structures.Note, error) {
+	return orgJSON[structures.Note](s, "POST", t.path, &structures.Note{ParentID: parentID, Text: &text})
+}
+
+// Replaces the text of the note
+func (s *HTTPClient) UpdateNote(t NoteTarget, id int, text structures.TextContent) (*structures.Note, error) {
+	return orgJSON[structures.Note](s, "PUT", fmt.Sprintf("%s/%d", t.path, id), &structures.Note{ID: id, Text: &text})
+}
+
+func (s *HTTPClient) DeleteNote(t NoteTarget, id int) error {
+	return s.orgCall("DELETE", fmt.Sprintf("%s/%d", t.path, id), nil)
+}
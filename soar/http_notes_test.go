@@ -0,0 +1,66 @@
+package soar
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestNotes(t *testing.T) {
+	var requests []string
+	var bodies []structures.Note
+	client := restClient(t, func(req *http.Request) (int, any) {
+		requests = append(requests, req.Method+" "+orgPath(req))
+		if req.Method == "GET" {
+			return 200, json.RawMessage(`[{"id": 1, "text": "<div>Escalated</div>", "children": [{"id": 2, "parent_id": 1, "text": "Ack"}]}]`)
+		}
+		var note structures.Note
+		if req.Body != nil {
+			json.NewDecoder(req.Body).Decode(&note)
+			bodies = append(bodies, note)
+		}
+		note.ID = 3
+		return 200, note
+	})
+
+	notes, err := client.GetNotes(IncidentNotes(2095))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].Text.Content != "<div>Escalated</div>" || notes[0].Children[0].ParentID != 1 {
+		t.Errorf("Unexpected notes: %+v", notes)
+	}
+	if _, err := client.CreateNote(IncidentNotes(2095), TextNote("Enriched")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReplyNote(TaskNotes(17), 1, NewHTML().Bold("Done").Note()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateNote(TaskNotes(17), 3, TextNote("Edited")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteNote(IncidentNotes(2095), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRequests := []string{
+		"GET incidents/2095/comments",
+		"POST incidents/2095/comments",
+		"POST tasks/17/comments",
+		"PUT tasks/17/comments/3",
+		"DELETE incidents/2095/comments/3",
+	}
+	for i, want := range wantRequests {
+		if i >= len(requests) || requests[i] != want {
+			t.Fatalf("Expected requests %v, got %v", wantRequests, requests)
+		}
+	}
+	if bodies[0].Text.Format != "text" || bodies[0].Text.Content != "Enriched" {
+		t.Errorf("Unexpected note body: %+v", bodies[0])
+	}
+	if bodies[1].ParentID != 1 || bodies[1].Text.Format != "html" || bodies[1].Text.Content != "<b>Done</b>" {
+		t.Errorf("Unexpected reply body: %+v", bodies[1])
+	}
+}
@@ -0,0 +1,153 @@
+package soar
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type HTTPOption func(*HTTPClient) error
+type HTTPOpts struct{}
+
+var HTTP = HTTPOpts{}
+
+// Trusts certificates of the PEM bundle (e.g. an internal CA) in addition to the system ones
+func (HTTPOpts) CACertPEM(pem []byte) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("No certificates found in CA PEM bundle")
+		}
+		c.TLSConfig.RootCAs = pool
+		return nil
+	}
+}
+
+// Trusts only certificates of the pool
+func (HTTPOpts) CertPool(pool *x509.CertPool) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.TLSConfig.RootCAs = pool
+		return nil
+	}
+}
+
+// Client certificate for mutual TLS, presented to both REST and STOMP endpoints
+func (HTTPOpts) ClientCert(cert tls.Certificate) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.TLSConfig.Certificates = append(c.TLSConfig.Certificates, cert)
+		return nil
+	}
+}
+
+// Client certificate for mutual TLS from PEM encoded certificate and key
+func (HTTPOpts) ClientCertPEM(certPEM, keyPEM []byte) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("Invalid client certificate: %w", err)
+		}
+		c.TLSConfig.Certificates = append(c.TLSConfig.Certificates, cert)
+		return nil
+	}
+}
+
+// Proxy for REST requests, an empty URL means the one from HTTPS_PROXY/NO_PROXY environment
+func (HTTPOpts) Proxy(proxyURL string) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		if proxyURL == "" {
+			c.proxy = http.ProxyFromEnvironment
+			return nil
+		}
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("Invalid proxy URL: %w", err)
+		}
+		c.proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+// HTTPS port of SOAR REST API, 443 by default
+func (HTTPOpts) Port(port int) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.Port = fmt.Sprint(port)
+		return nil
+	}
+}
+
+// Path prefix in front of /rest/, for SOAR published under a path of a reverse proxy
+func (HTTPOpts) BasePath(path string) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.BasePath = path
+		return nil
+	}
+}
+
+// Timeout of a single REST request attempt, 5 seconds by default
+func (HTTPOpts) Timeout(d time.Duration) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		if d < 0 {
+			return fmt.Errorf("Negative timeout: %v", d)
+		}
+		c.Client.Timeout = d
+		return nil
+	}
+}
+
+// Timeout of attachment uploads and downloads, which are not limited by Timeout
+func (HTTPOpts) FileTimeout(d time.Duration) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		if d < 0 {
+			return fmt.Errorf("Negative timeout: %v", d)
+		}
+		c.FileTimeout = d
+		return nil
+	}
+}
+
+// User-Agent header of REST requests
+func (HTTPOpts) UserAgent(ua string) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.UserAgent = ua
+		return nil
+	}
+}
+
+// Authenticator of REST requests instead of the API key, e.g. NewPasswordAuth for user sessions
+func (HTTPOpts) Auth(a Authenticator) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.Auth = a
+		return nil
+	}
+}
+
+// Logs in as a user with email and password instead of the API key
+func (HTTPOpts) Password(email, password string) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.Auth = NewPasswordAuth(email, password)
+		return nil
+	}
+}
+
+// Organization to work with, by name, ID or UUID; required when the credentials belong to several organizations
+func (HTTPOpts) Org(selector string) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.orgSelector = selector
+		return nil
+	}
+}
+
+// Retry policy of REST requests, DefaultRetryPolicy by default, nil disables retries
+func (HTTPOpts) Retry(p *RetryPolicy) func(*HTTPClient) error {
+	return func(c *HTTPClient) error {
+		c.Retry = p
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package soar
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHTTPOptions(t *testing.T) {
+	var gotPath, gotAgent string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAgent = r.URL.Path, r.Header.Get("User-Agent")
+		w.Write([]byte(`{"api_key_handle": 7, "orgs": [{"id": 201}]}`))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	client, err := NewHTTPClient(context.Background(), u.Hostname(), "id", "secret", false,
+		HTTP.CertPool(pool),
+		HTTP.Port(mustPort(t, u)),
+		HTTP.BasePath("/soar/"),
+		HTTP.UserAgent("unit-test/1.0"),
+	)
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	if gotPath != "/soar/rest/session" {
+		t.Errorf("Expected base path in front of /rest/, got %q", gotPath)
+	}
+	if gotAgent != "unit-test/1.0" {
+		t.Errorf("Expected custom User-Agent, got %q", gotAgent)
+	}
+	if client.Org.ID != 201 {
+		t.Errorf("Expected org 201, got %d", client.Org.ID)
+	}
+
+	// Without the CA the self-signed certificate of the test server is rejected
+	_, err = NewHTTPClient(context.Background(), u.Hostname(), "id", "secret", false,
+		HTTP.Port(mustPort(t, u)),
+		HTTP.Retry(nil),
+	)
+	if err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("Expected certificate verification error, got %v", err)
+	}
+}
+
+func TestHTTPOptionErrors(t *testing.T) {
+	tests := map[string]HTTPOption{
+		"ca":      HTTP.CACertPEM([]byte("not a certificate")),
+		"cert":    HTTP.ClientCertPEM([]byte("cert"), []byte("key")),
+		"proxy":   HTTP.Proxy("http://[::1"),
+		"timeout": HTTP.Timeout(-1),
+	}
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewHTTPClient(context.Background(), "test.local", "id", "secret", false, opt)
+			if err == nil {
+				t.Error("Expected option error")
+			}
+		})
+	}
+}
+
+func mustPort(t *testing.T, u *url.URL) int {
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatalf("Invalid port in %s: %v", u, err)
+	}
+	return port
+}
+
+func TestStompTLSConfig(t *testing.T) {
+	pool := x509.NewCertPool()
+	h := &HTTPClient{TLSConfig: &tls.Config{}}
+	if err := HTTP.CertPool(pool)(h); err != nil {
+		t.Fatal(err)
+	}
+	l := &StompListener{HTTPClient: h, Insecure: true}
+	config := l.tlsConfig()
+	if config.RootCAs != pool {
+		t.Error("Expected STOMP connection to reuse the CA pool of HTTPClient")
+	}
+	if !config.InsecureSkipVerify {
+		t.Error("Expected Insecure to skip certificate verification")
+	}
+	if h.TLSConfig.InsecureSkipVerify {
+		t.Error("Expected HTTPClient TLS config to stay untouched")
+	}
+}
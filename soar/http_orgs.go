@@ -0,0 +1,79 @@
+package soar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Organizations available to the session credentials
+func (s *HTTPClient) Orgs() []structures.Org {
+	if s.Session == nil {
+		return nil
+	}
+	return s.Session.Orgs
+}
+
+// Switches the client to the organization matching selector (name, ID or UUID).
+// An empty selector is allowed only when the session has a single organization
+func (s *HTTPClient) SelectOrg(selector string) error {
+	org, err := findOrg(s.Orgs(), selector)
+	if err != nil {
+		return err
+	}
+	s.Org = org
+	return nil
+}
+
+// Copy of the client bound to another organization, sharing the session and the connection pool
+func (s *HTTPClient) ForOrg(selector string) (*HTTPClient, error) {
+	org, err := findOrg(s.Orgs(), selector)
+	if err != nil {
+		return nil, err
+	}
+	c := *s
+	c.Org = org
+	return &c, nil
+}
+
+func findOrg(orgs []structures.Org, selector string) (*structures.Org, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		if len(orgs) == 1 {
+			return &orgs[0], nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrAmbiguousOrg, listOrgs(orgs))
+	}
+	id, idErr := strconv.Atoi(selector)
+	var found []int
+	for i, org := range orgs {
+		switch {
+		case idErr == nil && org.ID == id,
+			org.UUID != "" && strings.EqualFold(org.UUID, selector):
+			return &orgs[i], nil
+		case strings.EqualFold(org.Name, selector):
+			found = append(found, i)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q, available: %s", ErrOrgNotFound, selector, listOrgs(orgs))
+	case 1:
+		return &orgs[found[0]], nil
+	}
+	return nil, fmt.Errorf("%w: name %q is not unique, use ID or UUID: %s", ErrAmbiguousOrg, selector, listOrgs(orgs))
+}
+
+// "Name (ID 201, UUID ...)" list for error messages
+func listOrgs(orgs []structures.Org) string {
+	if len(orgs) == 0 {
+		return "none"
+	}
+	items := make([]string, len(orgs))
+	for i, org := range orgs {
+		items[i] = fmt.Sprintf("%q (ID %d, UUID %s)", org.Name, org.ID, org.UUID)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,71 @@
+package soar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestSelectOrg(t *testing.T) {
+	client := &HTTPClient{Session: &structures.SessionResponseJson{Orgs: []structures.Org{
+		{ID: 201, Name: "Acme", UUID: "a1"},
+		{ID: 202, Name: "Globex", UUID: "b2"},
+		{ID: 203, Name: "Twin", UUID: "c3"},
+		{ID: 204, Name: "twin", UUID: "d4"},
+	}}}
+	tests := []struct {
+		selector string
+		id       int
+		err      error
+	}{
+		{"202", 202, nil},
+		{"acme", 201, nil},
+		{"B2", 202, nil},
+		{"d4", 204, nil},
+		{"", 0, ErrAmbiguousOrg},
+		{"Twin", 0, ErrAmbiguousOrg},
+		{"Initech", 0, ErrOrgNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			err := client.SelectOrg(tt.selector)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), `"Globex" (ID 202, UUID b2)`) {
+					t.Errorf("Expected available orgs in error, got %v", err)
+				}
+				return
+			}
+			if client.Org.ID != tt.id {
+				t.Errorf("Expected org %d, got %d", tt.id, client.Org.ID)
+			}
+		})
+	}
+}
+
+func TestForOrg(t *testing.T) {
+	client := &HTTPClient{Session: &structures.SessionResponseJson{Orgs: []structures.Org{
+		{ID: 201, Name: "Acme"},
+		{ID: 202, Name: "Globex"},
+	}}}
+	if err := client.SelectOrg("Acme"); err != nil {
+		t.Fatal(err)
+	}
+	globex, err := client.ForOrg("Globex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if globex.Org.ID != 202 || client.Org.ID != 201 {
+		t.Errorf("Expected independent org selection, got %d and %d", globex.Org.ID, client.Org.ID)
+	}
+	if globex.Session != client.Session {
+		t.Error("Expected derived client to share the session")
+	}
+	if _, err := client.ForOrg("Initech"); !errors.Is(err, ErrOrgNotFound) {
+		t.Errorf("Expected ErrOrgNotFound, got %v", err)
+	}
+}
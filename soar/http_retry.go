@@ -0,0 +1,148 @@
+package soar
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Retry policy of HTTPClient requests
+type RetryPolicy struct {
+	// Total number of attempts, the first one included; 1 or less disables retries
+	MaxAttempts int
+	// Delays between attempts, a longer Retry-After of the response takes precedence
+	Backoff Backoff
+	// Response statuses worth retrying
+	Statuses []int
+	// Retry POST and PATCH requests too, which SOAR may have applied before failing
+	RetryNonIdempotent bool
+}
+
+// Retry policy used by NewHTTPClient: rate limiting and unavailability during upgrades
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	Backoff: Backoff{
+		Initial:    500 * time.Millisecond,
+		Max:        10 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+	},
+	Statuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// Whether the request may be sent more than once
+func (p *RetryPolicy) allows(req *http.Request) bool {
+	if p == nil || p.MaxAttempts <= 1 {
+		return false
+	}
+	// a body that cannot be rewound (e.g. a streamed upload) is sent once
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+func (p *RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return transientError(err)
+	}
+	return slices.Contains(p.Statuses, resp.StatusCode)
+}
+
+// Whether a transport error may go away on its own: timeouts and refused or reset connections.
+// Certificate, DNS and other permanent failures are reported at once
+func transientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// Sends the request, repeating it according to the Retry policy
+func (s *HTTPClient) do(req *http.Request) (*http.Response, error) {
+	if !s.Retry.allows(req) {
+		return s.Client.Do(req)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := s.Client.Do(req)
+		if attempt >= s.Retry.MaxAttempts || !s.Retry.retryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		delay := s.Retry.Backoff.Delay(attempt)
+		if resp != nil {
+			delay = max(delay, retryAfter(resp))
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		slog.Debug("Retrying SOAR request",
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Delay requested by Retry-After header (seconds or HTTP date), 0 if absent
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// Runs a read-modify-write operation, repeating it while SOAR reports a conflict (409).
+// apply must re-read the object on every call so that changes are reapplied on the fresh version
+func (s *HTTPClient) RetryConflict(attempts int, apply func() error) error {
+	backoff := DefaultRetryPolicy.Backoff
+	if s.Retry != nil {
+		backoff = s.Retry.Backoff
+	}
+	for attempt := 1; ; attempt++ {
+		err := apply()
+		if err == nil || !IsConflict(err) || attempt >= attempts {
+			return err
+		}
+		timer := time.NewTimer(backoff.Delay(attempt))
+		select {
+		case <-s.Ctx.Done():
+			timer.Stop()
+			return s.Ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package soar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Number of attempts of task changes conflicting with concurrent edits
+const taskUpdateAttempts = 3
+
+// Tasks of the incident
+func (s *HTTPClient) GetTasks(incID int) ([]structures.Task, error) {
+	tasks, err := orgJSON[structures.EntityList[structures.Task]](s, "GET", fmt.Sprintf("incidents/%d/tasks", incID), nil)
+	if err != nil {
+		return nil, err
+	}
+	return *tasks, nil
+}
+
+// Task details, instructions included
+func (s *HTTPClient) GetTask(id int) (*structures.Task, error) {
+	return orgJSON[structures.Task](s, "GET", fmt.Sprintf("tasks/%d", id), nil)
+}
+
+// Creates a custom task, see NewTask for phase and owner given by name
+func (s *HTTPClient) CreateTask(incID int, t *structures.Task) (*structures.Task, error) {
+	return orgJSON[structures.Task](s, "POST", fmt.Sprintf("incidents/%d/tasks", incID), t)
+}
+
+// Custom task with HTML instructions in the named phase, owner (email, full name or ID) is optional
+func (s *HTTPClient) NewTask(name, instructions, phase, owner string) (*structures.Task, error) {
+	phaseID, err := s.PhaseID(phase)
+	if err != nil {
+		return nil, err
+	}
+	t := &structures.Task{Name: name, PhaseID: phaseID, Instructions: &structures.TextContent{Format: "html", Content: instructions}}
+	if owner != "" {
+		if t.OwnerID, err = s.UserID(owner); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Sets the task owner by email, full name or ID
+func (s *HTTPClient) AssignTask(id int, owner string) (*structures.Task, error) {
+	ownerID, err := s.UserID(owner)
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdateTask(id, func(t *structures.Task) { t.OwnerID = ownerID })
+}
+
+// Removes the task owner
+func (s *HTTPClient) UnassignTask(id int) (*structures.Task, error) {
+	return s.UpdateTask(id, func(t *structures.Task) { t.OwnerID = 0 })
+}
+
+func (s *HTTPClient) CompleteTask(id int) (*structures.Task, error) {
+	return s.UpdateTask(id, func(t *structures.Task) { t.Status = structures.TaskStatusClosed })
+}
+
+func (s *HTTPClient) ReopenTask(id int) (*structures.Task, error) {
+	return s.UpdateTask(id, func(t *structures.Task) { t.Status = structures.TaskStatusOpen })
+}
+
+// Reads the task, applies change and saves it, repeating on conflicts with concurrent edits.
+// Task attributes not modeled by structures.Task are sent back as read, a field changed to its zero value is cleared
+func (s *HTTPClient) UpdateTask(id int, change func(*structures.Task)) (*structures.Task, error) {
+	var updated *structures.Task
+	err := s.RetryConflict(taskUpdateAttempts, func() error {
+		t, err := s.GetTask(id)
+		if err != nil {
+			return err
+		}
+		body, err := changedTask(t, change)
+		if err != nil {
+			return err
+		}
+		updated, err = orgJSON[structures.Task](s, "PUT", fmt.Sprintf("tasks/%d", id), body)
+		return err
+	})
+	return updated, err
+}
+
+// Task JSON as read with the fields modified by change replaced, zero values are sent as null
+func changedTask(t *structures.Task, change func(*structures.Task)) (map[string]any, error) {
+	body := map[string]any{}
+	// a separate copy, change may modify maps and slices of t in place
+	before := &structures.Task{}
+	if len(t.Raw) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(t.Raw))
+		dec.UseNumber()
+		if err := dec.Decode(&body); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(t.Raw, before); err != nil {
+			return nil, err
+		}
+	}
+	change(t)
+	bv, av := reflect.ValueOf(before).Elem(), reflect.ValueOf(t).Elem()
+	for i := range av.NumField() {
+		name, _, _ := strings.Cut(av.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || reflect.DeepEqual(bv.Field(i).Interface(), av.Field(i).Interface()) {
+			continue
+		}
+		if av.Field(i).IsZero() {
+			body[name] = nil
+		} else {
+			body[name] = av.Field(i).Interface()
+		}
+	}
+	return body, nil
+}
+
+// Phases of the organization, cached after the first call (see ResetCache)
+func (s *HTTPClient) GetPhases() ([]structures.Phase, error) {
+	phases, err := cachedJSON[structures.EntityList[structures.Phase]](s, "phases")
+	if err != nil {
+		return nil, err
+	}
+	return *phases, nil
+}
+
+// Phase ID by its name or ID
+func (s *HTTPClient) PhaseID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	phases, err := s.GetPhases()
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range phases {
+		if strings.EqualFold(p.Name, name) {
+			return p.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown phase %q", name)
+}
+
+// Users of the organization, cached after the first call (see ResetCache)
+func (s *HTTPClient) GetUsers() ([]structures.User, error) {
+	users, err := cachedJSON[structures.EntityList[structures.User]](s, "users")
+	if err != nil {
+		return nil, err
+	}
+	return *users, nil
+}
+
+// User ID by email, full name ("First Last") or ID
+func (s *HTTPClient) UserID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	users, err := s.GetUsers()
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Email, name) || strings.EqualFold(u.FirstName+" "+u.LastName, name) {
+			return u.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown user %q", name)
+}
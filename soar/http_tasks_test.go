@@ -0,0 +1,91 @@
+package soar
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestTasks(t *testing.T) {
+	// attributes structures.Task does not model must survive updates
+	task := map[string]any{"id": 17, "name": "Block sender", "inc_id": 2095, "status": "O", "vers": 1,
+		"description": "Block the sender domain", "category_id": 3}
+	conflicts := 1
+	client := restClient(t, func(req *http.Request) (int, any) {
+		switch req.Method + " " + orgPath(req) {
+		case "GET incidents/2095/tasks":
+			return 200, []any{task}
+		case "GET tasks/17":
+			return 200, task
+		case "PUT tasks/17":
+			if conflicts > 0 {
+				conflicts--
+				task["vers"] = 2
+				return 409, map[string]any{"message": "Task was modified"}
+			}
+			body := map[string]any{}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body["description"] != "Block the sender domain" || body["category_id"] != float64(3) {
+				t.Errorf("Expected unmodeled attributes to be kept, got %v", body)
+			}
+			body["vers"] = body["vers"].(float64) + 1
+			task = body
+			return 200, task
+		case "POST incidents/2095/tasks":
+			var t structures.Task
+			json.NewDecoder(req.Body).Decode(&t)
+			t.ID = 18
+			return 200, t
+		case "GET phases":
+			return 200, json.RawMessage(`{"entities": [{"id": 1000, "name": "Engage"}, {"id": 1002, "name": "Respond"}]}`)
+		case "GET users":
+			return 200, []structures.User{{ID: 4, Email: "analyst@example.com", FirstName: "Ana", LastName: "Lyst"}}
+		}
+		return 404, nil
+	})
+	client.Retry = &RetryPolicy{}
+
+	tasks, err := client.GetTasks(2095)
+	if err != nil || len(tasks) != 1 || tasks[0].Name != "Block sender" {
+		t.Fatalf("Unexpected tasks %+v, %v", tasks, err)
+	}
+	assigned, err := client.AssignTask(17, "Ana Lyst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assigned.OwnerID != 4 || conflicts != 0 {
+		t.Errorf("Expected task assigned after a conflict, got %+v", assigned)
+	}
+	if done, err := client.CompleteTask(17); err != nil || done.Status != structures.TaskStatusClosed || done.OwnerID != 4 {
+		t.Errorf("Expected closed task, got %+v, %v", done, err)
+	}
+	if open, err := client.ReopenTask(17); err != nil || open.Status != structures.TaskStatusOpen {
+		t.Errorf("Expected open task, got %+v, %v", open, err)
+	}
+	if unassigned, err := client.UnassignTask(17); err != nil || unassigned.OwnerID != 0 {
+		t.Errorf("Expected unassigned task, got %+v, %v", unassigned, err)
+	}
+	if owner, ok := task["owner_id"]; !ok || owner != nil {
+		t.Errorf("Expected owner_id to be cleared with null, got %v", task)
+	}
+
+	newTask, err := client.NewTask("Collect logs", "<p>Export proxy logs</p>", "respond", "analyst@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := client.CreateTask(2095, newTask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 18 || created.PhaseID != 1002 || created.OwnerID != 4 || created.Instructions.Content != "<p>Export proxy logs</p>" {
+		t.Errorf("Unexpected created task: %+v", created)
+	}
+	if _, err := client.NewTask("Collect logs", "", "Unknown", ""); err == nil {
+		t.Error("Expected unknown phase error")
+	}
+	if _, err := client.AssignTask(17, "nobody@example.com"); err == nil {
+		t.Error("Expected unknown user error")
+	}
+}
@@ -3,11 +3,18 @@ package soar
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 	"github.com/chmele/ibm-soar/soar/structures"
 )
 
@@ -19,6 +26,40 @@ func (m *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return m.roundTripFunc(req), nil
 }
 
+// Client of org 201 answering org requests with handler, the returned body is encoded as JSON unless it is []byte
+func restClient(t *testing.T, handler func(req *http.Request) (int, any)) *HTTPClient {
+	return &HTTPClient{
+		Hostname: "test.local",
+		Org:      &structures.Org{ID: 201},
+		Ctx:      context.Background(),
+		Client: http.Client{
+			Transport: &mockRoundTripper{
+				roundTripFunc: func(req *http.Request) *http.Response {
+					status, body := handler(req)
+					data, ok := body.([]byte)
+					if !ok {
+						var err error
+						if data, err = json.Marshal(body); err != nil {
+							t.Fatalf("Cannot encode response: %v", err)
+						}
+					}
+					return &http.Response{
+						StatusCode: status,
+						Body:       io.NopCloser(bytes.NewReader(data)),
+						Header:     make(http.Header),
+						Request:    req,
+					}
+				},
+			},
+		},
+	}
+}
+
+// Path of an org request relative to /rest/orgs/201/
+func orgPath(req *http.Request) string {
+	return strings.TrimPrefix(req.URL.Path, "/rest/orgs/201/")
+}
+
 func TestNewHTTPClient(t *testing.T) {
 	mockSession := &structures.SessionResponseJson{
 		APIKeyHandle: 444,
@@ -204,3 +245,181 @@ func TestGetMessageDestinationAvailable_Error(t *testing.T) {
 		t.Error("expected false availability for missing destination")
 	}
 }
+
+func TestAPIError(t *testing.T) {
+	client := &HTTPClient{
+		Org: &structures.Org{ID: 42},
+		Client: http.Client{
+			Transport: &mockRoundTripper{
+				roundTripFunc: func(req *http.Request) *http.Response {
+					return &http.Response{
+						StatusCode: 409,
+						Body: io.NopCloser(strings.NewReader(
+							`{"success":false,"title":"Conflict","message":"Incident was modified","hints":["Reload and retry"]}`)),
+						Header:  make(http.Header),
+						Request: req,
+					}
+				},
+			},
+		},
+		Ctx: context.Background(),
+	}
+
+	_, err := client.GetFunctions()
+	if !IsConflict(err) || IsNotFound(err) || IsForbidden(err) {
+		t.Fatalf("Expected conflict error, got: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %T", err)
+	}
+	if apiErr.Method != "GET" || apiErr.Path != "/rest/orgs/42/functions" || apiErr.Title != "Conflict" ||
+		apiErr.Message != "Incident was modified" || len(apiErr.Hints) != 1 {
+		t.Errorf("Unexpected APIError fields: %+v", apiErr)
+	}
+	expected := "SOAR API error 409 on GET /rest/orgs/42/functions: Conflict: Incident was modified (hints: Reload and retry)"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAPIErrorPlainBody(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 401,
+		Body:       io.NopCloser(strings.NewReader("unauthorized\n")),
+	}
+	err := checkResponse(resp)
+	if !IsUnauthorized(err) || !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected unauthorized error, got: %v", err)
+	}
+	if err.(*APIError).Message != "unauthorized" {
+		t.Errorf("Expected raw body as a message, got: %q", err.(*APIError).Message)
+	}
+}
+
+func TestRequestRetry(t *testing.T) {
+	fastRetry := &RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     Backoff{Initial: time.Millisecond},
+		Statuses:    []int{429, 503},
+	}
+	tests := []struct {
+		name     string
+		method   string
+		body     io.Reader
+		policy   *RetryPolicy
+		attempts int
+		status   int
+	}{
+		{"get retried", "GET", nil, fastRetry, 3, 200},
+		{"put body replayed", "PUT", strings.NewReader("payload"), fastRetry, 3, 200},
+		{"post not retried", "POST", strings.NewReader("payload"), fastRetry, 1, 429},
+		{"stream not retried", "PUT", io.MultiReader(strings.NewReader("payload")), fastRetry, 1, 429},
+		{"no policy", "GET", nil, nil, 1, 429},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			client := &HTTPClient{
+				Retry: tt.policy,
+				Client: http.Client{
+					Transport: &mockRoundTripper{
+						roundTripFunc: func(req *http.Request) *http.Response {
+							attempts++
+							if req.Body != nil {
+								if body, _ := io.ReadAll(req.Body); string(body) != "payload" {
+									t.Errorf("Expected replayed body, got %q", body)
+								}
+							}
+							status, header := 200, make(http.Header)
+							if attempts == 1 {
+								status = 429
+								header.Set("Retry-After", "0")
+							} else if attempts == 2 {
+								status = 503
+							}
+							return &http.Response{
+								StatusCode: status,
+								Body:       io.NopCloser(strings.NewReader("")),
+								Header:     header,
+							}
+						},
+					},
+				},
+				Ctx: context.Background(),
+			}
+			resp, err := client.Request(tt.method, "test", tt.body)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if attempts != tt.attempts || resp.StatusCode != tt.status {
+				t.Errorf("Expected %d attempts with status %d, got %d with %d", tt.attempts, tt.status, attempts, resp.StatusCode)
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestRequestRetryTransportErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, 3},
+		{"reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, 3},
+		{"timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, 3},
+		{"unknown host", &net.DNSError{Err: "no such host", IsNotFound: true}, 1},
+		{"bad certificate", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			client := &HTTPClient{
+				Retry: &RetryPolicy{MaxAttempts: 3, Backoff: Backoff{Initial: time.Millisecond}},
+				Client: http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					attempts++
+					return nil, tt.err
+				})},
+				Ctx: context.Background(),
+			}
+			if _, err := client.Request("GET", "test", nil); err == nil {
+				t.Fatal("Expected an error")
+			}
+			if attempts != tt.attempts {
+				t.Errorf("Expected %d attempts, got %d", tt.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryConflict(t *testing.T) {
+	client := &HTTPClient{
+		Retry: &RetryPolicy{Backoff: Backoff{Initial: time.Millisecond}},
+		Ctx:   context.Background(),
+	}
+	calls := 0
+	err := client.RetryConflict(3, func() error {
+		calls++
+		if calls < 3 {
+			return &APIError{StatusCode: 409}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = client.RetryConflict(3, func() error {
+		calls++
+		return &APIError{StatusCode: 400}
+	})
+	if !IsStatus(err, 400) || calls != 1 {
+		t.Errorf("Expected other errors not to be retried, got %v after %d calls", err, calls)
+	}
+}
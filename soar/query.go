@@ -0,0 +1,160 @@
+package soar
+
+import (
+	"iter"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Condition methods of query_paged filters
+const (
+	MethodEquals      = "equals"
+	MethodNotEquals   = "not_equals"
+	MethodIn          = "in"
+	MethodNotIn       = "not_in"
+	MethodGt          = "gt"
+	MethodGte         = "gte"
+	MethodLt          = "lt"
+	MethodLte         = "lte"
+	MethodContains    = "contains"
+	MethodNotContains = "not_contains"
+	MethodHasValue    = "has_a_value"
+	MethodNotHasValue = "not_has_a_value"
+)
+
+// Default number of objects requested per query_paged page
+const DefaultPageSize = 100
+
+// Filter and sort builder of query_paged searches, conditions are ANDed until Or starts another group:
+//
+//	NewQuery().Equals("plan_status", "A").Gte("create_date", since).OrderBy("create_date", true)
+type Query struct {
+	filters  []structures.Filter
+	sorts    []structures.Sort
+	pageSize int
+}
+
+func NewQuery() *Query {
+	return &Query{pageSize: DefaultPageSize}
+}
+
+// Adds a condition to the current group, time.Time values are sent as milliseconds since epoch
+func (q *Query) Where(field, method string, value any) *Query {
+	if t, ok := value.(time.Time); ok {
+		value = t.UnixMilli()
+	}
+	if len(q.filters) == 0 {
+		q.filters = append(q.filters, structures.Filter{})
+	}
+	last := &q.filters[len(q.filters)-1]
+	last.Conditions = append(last.Conditions, structures.Condition{FieldName: field, Method: method, Value: value})
+	return q
+}
+
+// Starts another group of conditions, matching objects satisfy at least one group
+func (q *Query) Or() *Query {
+	if len(q.filters) > 0 && len(q.filters[len(q.filters)-1].Conditions) > 0 {
+		q.filters = append(q.filters, structures.Filter{})
+	}
+	return q
+}
+
+func (q *Query) Equals(field string, value any) *Query {
+	return q.Where(field, MethodEquals, value)
+}
+
+func (q *Query) NotEquals(field string, value any) *Query {
+	return q.Where(field, MethodNotEquals, value)
+}
+
+func (q *Query) In(field string, values ...any) *Query {
+	return q.Where(field, MethodIn, values)
+}
+
+func (q *Query) NotIn(field string, values ...any) *Query {
+	return q.Where(field, MethodNotIn, values)
+}
+
+func (q *Query) Gt(field string, value any) *Query {
+	return q.Where(field, MethodGt, value)
+}
+
+func (q *Query) Gte(field string, value any) *Query {
+	return q.Where(field, MethodGte, value)
+}
+
+func (q *Query) Lt(field string, value any) *Query {
+	return q.Where(field, MethodLt, value)
+}
+
+func (q *Query) Lte(field string, value any) *Query {
+	return q.Where(field, MethodLte, value)
+}
+
+func (q *Query) Contains(field string, value any) *Query {
+	return q.Where(field, MethodContains, value)
+}
+
+func (q *Query) HasValue(field string) *Query {
+	return q.Where(field, MethodHasValue, nil)
+}
+
+func (q *Query) NotHasValue(field string) *Query {
+	return q.Where(field, MethodNotHasValue, nil)
+}
+
+// Sorts results by the field, sorts are applied in the order they are added
+func (q *Query) OrderBy(field string, desc bool) *Query {
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	q.sorts = append(q.sorts, structures.Sort{FieldName: field, Type: order})
+	return q
+}
+
+// Number of objects fetched per request
+func (q *Query) PageSize(n int) *Query {
+	if n > 0 {
+		q.pageSize = n
+	}
+	return q
+}
+
+// Request body of the page starting at start
+func (q *Query) page(start int) *structures.QueryPaged {
+	return &structures.QueryPaged{
+		Filters: q.filters,
+		Sorts:   q.sorts,
+		Start:   start,
+		Length:  q.pageSize,
+	}
+}
+
+// Streams query_paged results page by page, stopping at the first error.
+// Pages are fetched by offset, objects changed during iteration may be skipped or repeated
+func queryPaged[T any](s *HTTPClient, url string, q *Query) iter.Seq2[T, error] {
+	if q == nil {
+		q = NewQuery()
+	}
+	return func(yield func(T, error) bool) {
+		for start := 0; ; {
+			page, err := orgJSON[structures.QueryPagedResult[T]](s, "POST", url, q.page(start))
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			start += len(page.Data)
+			if len(page.Data) < q.pageSize || start >= page.RecordsFiltered {
+				return
+			}
+		}
+	}
+}
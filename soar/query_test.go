@@ -0,0 +1,96 @@
+package soar
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	since := time.UnixMilli(1700000000000)
+	q := NewQuery().
+		Equals("plan_status", "A").
+		Gte("create_date", since).
+		Or().
+		In("severity_code", 4, 5).
+		OrderBy("create_date", true).
+		PageSize(2)
+	got := q.page(4)
+	want := &structures.QueryPaged{
+		Filters: []structures.Filter{
+			{Conditions: []structures.Condition{
+				{FieldName: "plan_status", Method: "equals", Value: "A"},
+				{FieldName: "create_date", Method: "gte", Value: int64(1700000000000)},
+			}},
+			{Conditions: []structures.Condition{
+				{FieldName: "severity_code", Method: "in", Value: []any{4, 5}},
+			}},
+		},
+		Sorts:  []structures.Sort{{FieldName: "create_date", Type: "desc"}},
+		Start:  4,
+		Length: 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected query:\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestIncidentsPagination(t *testing.T) {
+	const total = 5
+	var starts []int
+	client := restClient(t, func(req *http.Request) (int, any) {
+		if req.Method != "POST" || orgPath(req) != "incidents/query_paged" {
+			t.Fatalf("Unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		var q structures.QueryPaged
+		json.NewDecoder(req.Body).Decode(&q)
+		starts = append(starts, q.Start)
+		page := structures.QueryPagedResult[structures.Incident]{RecordsTotal: total, RecordsFiltered: total}
+		for id := q.Start; id < min(q.Start+q.Length, total); id++ {
+			page.Data = append(page.Data, structures.Incident{ID: id})
+		}
+		return 200, page
+	})
+
+	var ids []int
+	for inc, err := range client.Incidents(NewQuery().PageSize(2)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, inc.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{0, 1, 2, 3, 4}) || !reflect.DeepEqual(starts, []int{0, 2, 4}) {
+		t.Errorf("Unexpected incidents %v fetched from offsets %v", ids, starts)
+	}
+
+	// breaking out of the loop stops fetching
+	starts = nil
+	for inc := range client.Incidents(NewQuery().PageSize(2)) {
+		if inc.ID == 0 {
+			break
+		}
+	}
+	if len(starts) != 1 {
+		t.Errorf("Expected a single page request, got %v", starts)
+	}
+}
+
+func TestIncidentsError(t *testing.T) {
+	client := restClient(t, func(req *http.Request) (int, any) {
+		return 403, map[string]any{"message": "Forbidden"}
+	})
+	n := 0
+	for _, err := range client.Incidents(nil) {
+		n++
+		if !IsForbidden(err) {
+			t.Errorf("Expected 403 error, got %v", err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("Expected a single error, got %d items", n)
+	}
+}
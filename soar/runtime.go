@@ -0,0 +1,50 @@
+package soar
+
+import (
+	"context"
+)
+
+// Handlers serving a single message destination of a Runtime
+type Destination struct {
+	// Message destination api name
+	Name     string
+	Handlers []ContextHandler
+}
+
+// App serving many message destinations with one STOMP connection (a subscription per destination)
+type Runtime struct {
+	Listener *StompListener
+}
+
+// Creates a runtime, checking that every destination exists and is available to the API key
+// (see NewStompListener for the errors)
+func NewRuntime(h *HTTPClient, destinations []Destination, opts ...StompOption) (*Runtime, error) {
+	for _, d := range destinations {
+		opts = append(opts, Stomp.Destination(d.Name, d.Handlers...))
+	}
+	l, err := NewStompListener(h, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{Listener: l}, nil
+}
+
+// Serves all destinations until ctx is cancelled (nil is returned) or the connection is lost for good
+func (r *Runtime) Run(ctx context.Context) error {
+	r.Listener.Ctx = ctx
+	if err := r.Listener.ListenContext(); err != nil {
+		return err
+	}
+	<-r.Listener.Done
+	return r.Listener.Err
+}
+
+// Closed once Run is over
+func (r *Runtime) Done() <-chan struct{} {
+	return r.Listener.Done
+}
+
+// Gracefully stops Run, see StompListener.Shutdown
+func (r *Runtime) Shutdown(ctx context.Context) ([]AbortedCall, error) {
+	return r.Listener.Shutdown(ctx)
+}
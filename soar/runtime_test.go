@@ -0,0 +1,85 @@
+package soar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func TestRuntimeValidatesDestinations(t *testing.T) {
+	client := &HTTPClient{
+		Session: &structures.SessionResponseJson{APIKeyHandle: 42},
+		Org:     &structures.Org{ID: 123},
+		Client: http.Client{
+			Transport: &mockRoundTripper{
+				roundTripFunc: func(req *http.Request) *http.Response {
+					md := structures.MessageDestination{APIKeys: []int{42}}
+					if strings.HasSuffix(req.URL.Path, "/forbidden") {
+						md.APIKeys = []int{7}
+					}
+					body, _ := json.Marshal(md)
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewReader(body)),
+						Header:     make(http.Header),
+					}
+				},
+			},
+		},
+		Ctx: context.Background(),
+	}
+
+	handlers := []ContextHandler{respondWith("ok")}
+	if _, err := NewRuntime(client, []Destination{{Name: "first", Handlers: handlers}, {Name: "second", Handlers: handlers}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err := NewRuntime(client, []Destination{{Name: "first", Handlers: handlers}, {Name: "forbidden", Handlers: handlers}})
+	if err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("Expected error for the forbidden destination, got: %v", err)
+	}
+	_, err = NewRuntime(client, []Destination{{Name: "empty"}})
+	if err == nil || !strings.Contains(err.Error(), "No handlers are given for message destination empty") {
+		t.Errorf("Expected error for the destination without handlers, got: %v", err)
+	}
+}
+
+func TestRuntimeDuplicateDestination(t *testing.T) {
+	_, err := NewRuntime(&HTTPClient{}, []Destination{
+		{Name: "fn", Handlers: []ContextHandler{respondWith("first")}},
+		{Name: "fn", Handlers: []ContextHandler{respondWith("second")}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "fn is configured twice") {
+		t.Errorf("Expected duplicate destination error, got: %v", err)
+	}
+}
+
+func TestRuntimeSharedConnection(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	listener.MessageDestination = ""
+	listener.Destinations = map[string][]ContextHandler{
+		"first":  {respondWith("from first")},
+		"second": {respondWith("from second")},
+	}
+	runtime := &Runtime{Listener: listener}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runtime.Run(ctx)
+
+	WaitWritten(t, conn, "SUBSCRIBE\ndestination:actions.123.first\n")
+	WaitWritten(t, conn, "SUBSCRIBE\ndestination:actions.123.second\n")
+	for _, md := range []string{"first", "second"} {
+		conn.Feed([]byte("MESSAGE\ndestination:actions.123." + md + "\nsubscription:actions.123." + md +
+			"\nmessage-id:" + md + "\ncorrelation-id:" + md + "\n\n{}\x00"))
+	}
+	WaitWritten(t, conn, "destination:acks.123.first\ncontent-type:application/json\ncorrelation-id:first\n\n{\"message_type\":0,\"message\":\"from first\"")
+	WaitWritten(t, conn, "destination:acks.123.second\ncontent-type:application/json\ncorrelation-id:second\n\n{\"message_type\":0,\"message\":\"from second\"")
+	conn.Close()
+	<-runtime.Done()
+}
@@ -8,89 +8,262 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/go-stomp/stomp/v3"
 	"github.com/chmele/ibm-soar/soar/structures"
+	"github.com/go-stomp/stomp/v3"
+	"github.com/go-stomp/stomp/v3/frame"
 )
 
-// Structure representing a single STOMP connection to a single SOAR message destination
+// Structure representing a single STOMP connection to SOAR message destinations,
+// MessageDestination is served by Listen handlers and Destinations by their own ones
 type StompListener struct {
-	HTTPClient         *HTTPClient
-	StompPort          string
-	MessageDestination string
-	Ctx                context.Context
-	Done               chan struct{}
-	Insecure           bool
-	Subscription       *stomp.Subscription
-	Conn               *stomp.Conn
-	Logger             *slog.Logger
-}
-
-// Creates a stomp listener with connectivity and access check
+	HTTPClient          *HTTPClient
+	StompPort           string
+	MessageDestination  string
+	Destinations        map[string][]ContextHandler
+	Ctx                 context.Context
+	Done                chan struct{}
+	Insecure            bool
+	Subscription        *stomp.Subscription
+	Conn                *stomp.Conn
+	Logger              *slog.Logger
+	AckMode             AckMode
+	PrefetchSize        int
+	MaxConcurrency      int
+	FunctionConcurrency map[string]int
+	HeartBeatSend       time.Duration
+	HeartBeatRecv       time.Duration
+	Reconnect           *Backoff
+	OnEvent             func(ListenerEvent)
+	ErrorMapper         ErrorMapper
+	Timeout             time.Duration
+	StatusInterval      time.Duration
+	FunctionTimeouts    map[string]time.Duration
+	Metrics             Metrics
+	Err                 error
+	dial                func() (net.Conn, error)
+	heartBeatError      time.Duration
+	pool                *workerPool
+	routes              []*route
+	stopping            chan struct{}
+	stopOnce            sync.Once
+	initOnce            sync.Once
+	startMu             sync.Mutex
+	starting            bool
+	started             chan struct{}
+	startErr            error
+	calls               sync.WaitGroup
+	inflight            sync.Map
+	callCtx             context.Context
+	abort               context.CancelFunc
+}
+
+// Creates a stomp listener with connectivity and access check: every message destination must exist
+// and list the API key among its users, otherwise ErrDestinationNotFound, ErrAPIKeyNotAuthorized
+// or ErrUnauthorized is returned (wrapped)
 func NewStompListener(h *HTTPClient, opts ...StompOption) (*StompListener, error) {
+	backoff := DefaultBackoff
 	ret := &StompListener{
-		HTTPClient: h,
-		StompPort:  "65001",
-		Ctx:        context.Background(),
-		Done:       make(chan struct{}),
-		Insecure:   false,
+		HTTPClient:     h,
+		StompPort:      "65001",
+		Ctx:            context.Background(),
+		Done:           make(chan struct{}),
+		Insecure:       false,
+		Logger:         slog.Default(),
+		StatusInterval: DefaultStatusInterval,
+		Reconnect:      &backoff,
 	}
+	ret.init()
 	for _, opt := range opts {
 		err := opt(ret)
 		if err != nil {
 			return nil, err
 		}
 	}
+	// a client logged in with a user session still needs KeyId and KeySecret for STOMP
+	if h.Auth != nil && h.KeyId == "" {
+		return nil, ErrAPIKeyRequired
+	}
+	if err := ret.checkAccess(); err != nil {
+		return nil, err
+	}
 	return ret, nil
 }
 
-// Main entry point for stomp listening
+// Verifies every message destination of the listener against SOAR REST API
+func (l *StompListener) checkAccess() error {
+	names := slices.Sorted(maps.Keys(l.Destinations))
+	if l.MessageDestination != "" {
+		names = append([]string{l.MessageDestination}, names...)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	client, err := l.apiKeyClient()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := client.CheckMessageDestination(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Client checking access as the API key STOMP logs in with. The session of a client with another
+// Authenticator (e.g. a user login) has no API key handle, so the key's own session is fetched
+func (l *StompListener) apiKeyClient() (*HTTPClient, error) {
+	if _, isKey := l.HTTPClient.Auth.(APIKeyAuth); l.HTTPClient.Auth == nil || isKey {
+		return l.HTTPClient, nil
+	}
+	c := *l.HTTPClient
+	c.Auth = nil
+	session, err := c.GetOrg()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot check STOMP API key: %w", err)
+	}
+	c.Session = session
+	return &c, nil
+}
+
+// Main entry point for stomp listening, the first connection is made synchronously,
+// the rest of the listener lifetime (including reconnects) is supervised in background until Done is closed
 func (l *StompListener) Listen(f ...FunctionCallHandler) error {
-	netConn, err := l.connectTLS()
+	handlers := make([]ContextHandler, len(f))
+	for i := range f {
+		handlers[i] = WithContext(f[i])
+	}
+	return l.ListenContext(handlers...)
+}
+
+// Same as Listen for context-aware handlers. A handler context is derived from Ctx,
+// limited by Timeout or FunctionTimeouts and cancelled when the call is over
+func (l *StompListener) ListenContext(f ...ContextHandler) (err error) {
+	l.init()
+	l.startMu.Lock()
+	if l.starting {
+		l.startMu.Unlock()
+		return errors.New("STOMP listener is already started")
+	}
+	if l.isStopping() {
+		l.startMu.Unlock()
+		return errStopping
+	}
+	l.starting = true
+	l.startMu.Unlock()
+	// Shutdown waits for the start to either launch the supervisor or fail
+	defer func() {
+		l.startErr = err
+		close(l.started)
+	}()
+
+	routes, err := l.buildRoutes(f)
+	if err != nil {
+		return err
+	}
+	l.routes = routes
+	l.pool = newWorkerPool(l.MaxConcurrency, l.FunctionConcurrency)
+	if err := l.connect(); err != nil {
+		return err
+	}
+	if l.isStopping() {
+		l.Logger.Info("STOMP Disconnecting")
+		l.Conn.Disconnect()
+		return errStopping
+	}
+	l.callCtx, l.abort = context.WithCancel(l.Ctx)
+	go func() {
+		defer close(l.Done)
+		l.Err = l.supervise()
+	}()
+	return nil
+}
+
+// Creates the channels of the listener lifecycle, also for listeners built without NewStompListener
+func (l *StompListener) init() {
+	l.initOnce.Do(func() {
+		if l.stopping == nil {
+			l.stopping = make(chan struct{})
+		}
+		l.started = make(chan struct{})
+	})
+}
+
+// Dials the broker, performs STOMP handshake and subscribes to the function queue
+func (l *StompListener) connect() error {
+	dial := l.dial
+	if dial == nil {
+		dial = l.connectTLS
+	}
+	netConn, err := dial()
 	if err != nil {
 		return err
 	}
 
 	if err := l.connectSTOMP(netConn); err != nil {
+		netConn.Close()
 		return err
 	}
 	l.Logger.Info("Connected to STOMP")
 
 	if err := l.subscribe(); err != nil {
+		l.Conn.MustDisconnect()
 		return err
 	}
-	l.Logger.Info("Subscribed to queue",
-		slog.String("message_destination", l.MessageDestination))
-	go func() {
-		defer close(l.Done)
-		l.stompLoop(f...)
-	}()
+	for _, r := range l.routes {
+		l.Logger.Info("Subscribed to queue",
+			slog.String("message_destination", r.destination))
+	}
+	l.emit(ListenerEvent{Type: EventConnected})
 	return nil
 }
 
-// Fancy stuff for supporting insecure connections
+// TLS connection to the STOMP port, configured the same way as REST requests of HTTPClient
 func (l *StompListener) connectTLS() (net.Conn, error) {
 	dialer := &net.Dialer{Timeout: 10 * time.Second}
 	return tls.DialWithDialer(
 		dialer,
 		"tcp",
-		fmt.Sprintf("%s:%s", l.HTTPClient.Hostname, l.StompPort),
-		&tls.Config{InsecureSkipVerify: l.Insecure},
+		net.JoinHostPort(l.HTTPClient.Hostname, l.StompPort),
+		l.tlsConfig(),
 	)
+}
 
+// HTTPClient TLS settings (CA bundle, client certificate), Insecure overrides the certificate check
+func (l *StompListener) tlsConfig() *tls.Config {
+	config := &tls.Config{}
+	if l.HTTPClient.TLSConfig != nil {
+		config = l.HTTPClient.TLSConfig.Clone()
+	}
+	if l.Insecure {
+		config.InsecureSkipVerify = true
+	}
+	return config
 }
 
 // Use stomp library in this func to set up Conn field
 func (l *StompListener) connectSTOMP(connection net.Conn) error {
-	conn, err := stomp.Connect(connection,
+	opts := []func(*stomp.Conn) error{
 		stomp.ConnOpt.Login(l.HTTPClient.KeyId, l.HTTPClient.KeySecret),
 		stomp.ConnOpt.AcceptVersion(stomp.V12),
 		stomp.ConnOpt.Host(l.HTTPClient.Hostname),
-		stomp.ConnOpt.HeartBeat(0, 0),
+		// a heart-beat missed by the broker closes the connection, which is then handled as any other disconnect
+		stomp.ConnOpt.HeartBeat(l.HeartBeatSend, l.HeartBeatRecv),
+		stomp.ConnOpt.ResponseHeaders(l.logHeartBeat),
 		stomp.ConnOpt.Logger(&StompLogger{l.Logger}),
-	)
+	}
+	if l.heartBeatError > 0 {
+		opts = append(opts, stomp.ConnOpt.HeartBeatError(l.heartBeatError))
+	}
+	conn, err := stomp.Connect(connection, opts...)
 	if err != nil {
 		return err
 	}
@@ -98,30 +271,93 @@ func (l *StompListener) connectSTOMP(connection net.Conn) error {
 	return nil
 }
 
-// Endless listening loop for message channel
-func (l *StompListener) stompLoop(f ...FunctionCallHandler) error {
+// Logs heart-beat intervals offered by the broker in CONNECTED frame
+func (l *StompListener) logHeartBeat(h *frame.Header) {
+	if l.HeartBeatSend == 0 && l.HeartBeatRecv == 0 {
+		return
+	}
+	hb, ok := h.Contains(frame.HeartBeat)
+	if !ok {
+		l.Logger.Warn("STOMP broker did not negotiate heart-beating")
+		return
+	}
+	send, recv, err := frame.ParseHeartBeat(hb)
+	if err != nil {
+		l.Logger.Warn("STOMP broker sent malformed heart-beat header", slog.String("heart_beat", hb))
+		return
+	}
+	l.Logger.Debug("STOMP heart-beating negotiated",
+		slog.Duration("broker_send", send),
+		slog.Duration("broker_recv", recv))
+}
+
+// Endless listening loop for message channels of all subscriptions
+func (l *StompListener) stompLoop() error {
 	defer func() {
 		l.Logger.Info("STOMP Disconnecting")
 		l.Conn.Disconnect()
 	}()
 	defer func() {
 		l.Logger.Info("STOMP Unsubscribing")
-		l.Subscription.Unsubscribe()
+		for _, r := range l.routes {
+			r.sub.Unsubscribe()
+		}
 	}()
 	errCh := make(chan error)
+	// lets in-flight calls finish after the loop is over instead of blocking on errCh
+	loopDone := make(chan struct{})
+	defer close(loopDone)
+	inbox := l.fanIn(loopDone)
+	slots := l.pool.slots()
 	for {
+		// a worker slot is taken before reading, so that calls beyond the limit stay with the broker
+		if slots != nil {
+			select {
+			case <-l.Ctx.Done():
+				l.Logger.Info("STOMP is shutting down")
+				return nil
+			case <-l.stopping:
+				l.drain()
+				return nil
+			case slots <- struct{}{}:
+			case err := <-errCh:
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
 		select {
 		case <-l.Ctx.Done():
+			l.pool.release()
 			l.Logger.Info("STOMP is shutting down")
 			return nil
-		case msg, ok := <-l.Subscription.C:
-			if !ok {
+		case <-l.stopping:
+			l.pool.release()
+			l.drain()
+			return nil
+		case d := <-inbox:
+			if d.msg == nil {
+				l.pool.release()
 				return errors.New("Attempted to read closed STOMP channel")
 			}
+			// connection loss or ERROR frame is delivered as a message
+			if d.msg.Err != nil {
+				l.pool.release()
+				return d.msg.Err
+			}
+			l.calls.Add(1)
 			go func() {
-				errCh <- l.handleFunc(f...)(msg)
+				err := l.handleFunc(d.route.handlers...)(d.msg)
+				l.pool.release()
+				l.calls.Done()
+				select {
+				case errCh <- err:
+				case <-loopDone:
+				}
 			}()
 		case err := <-errCh:
+			l.pool.release()
 			if err != nil {
 				return err
 			}
@@ -129,61 +365,315 @@ func (l *StompListener) stompLoop(f ...FunctionCallHandler) error {
 	}
 }
 
+// Function queue of a message destination along with the handlers serving it
+type route struct {
+	destination string
+	handlers    []ContextHandler
+	sub         *stomp.Subscription
+}
+
+// Message read from a route subscription, nil once the subscription channel is closed
+type delivery struct {
+	msg   *stomp.Message
+	route *route
+}
+
+// Routes of MessageDestination (served by f) and of Destinations, ordered by name
+func (l *StompListener) buildRoutes(f []ContextHandler) ([]*route, error) {
+	var routes []*route
+	if l.MessageDestination != "" {
+		if len(f) == 0 {
+			return nil, fmt.Errorf("No handlers are given for message destination %s", l.MessageDestination)
+		}
+		routes = append(routes, &route{destination: l.MessageDestination, handlers: f})
+	} else if len(f) > 0 {
+		return nil, errors.New("Handlers are given without a message destination")
+	}
+	for _, name := range slices.Sorted(maps.Keys(l.Destinations)) {
+		if name == l.MessageDestination {
+			return nil, fmt.Errorf("Message destination %s is configured twice", name)
+		}
+		if len(l.Destinations[name]) == 0 {
+			return nil, fmt.Errorf("No handlers are given for message destination %s", name)
+		}
+		routes = append(routes, &route{destination: name, handlers: l.Destinations[name]})
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("No message destination to listen to")
+	}
+	return routes, nil
+}
+
+// Merges messages of all subscriptions into a single channel until done is closed
+func (l *StompListener) fanIn(done chan struct{}) chan delivery {
+	inbox := make(chan delivery)
+	for _, r := range l.routes {
+		go func() {
+			for {
+				msg, ok := <-r.sub.C
+				if !ok {
+					msg = nil
+				}
+				select {
+				case inbox <- delivery{msg: msg, route: r}:
+				case <-done:
+					return
+				}
+				if msg == nil || msg.Err != nil {
+					return
+				}
+			}
+		}()
+	}
+	return inbox
+}
+
 // The function that is either writes the response to a message or returns an error
 type ProcessFunc func(*stomp.Message) error
 
 // The function used as a part of a response to message (updates the processing status, logs the message, etc.)
 type FunctionCallHandler func(*structures.FunctionCall) (*structures.FuncResponse, error)
 
+// FunctionCallHandler observing call cancellation: listener shutdown or a per-function timeout
+type ContextHandler func(context.Context, *structures.FunctionCall) (*structures.FuncResponse, error)
+
+// Adapts a FunctionCallHandler to ContextHandler, ignoring the context
+func WithContext(f FunctionCallHandler) ContextHandler {
+	return func(_ context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		return f(c)
+	}
+}
+
+// Converts a handler error into the completion reported to SOAR, FunctionCall is nil if the call could not be parsed
+type ErrorMapper func(*structures.FunctionCall, error) *structures.FuncResponse
+
+// Acknowledgement mode of the function queue subscription
+type AckMode = stomp.AckMode
+
+const (
+	// Calls are acknowledged by the broker upon delivery, a crash mid-handler loses the call
+	AckAuto = stomp.AckAuto
+	// Cumulative acknowledgement, an ACK covers every call delivered before; unsafe with concurrent handlers
+	AckClient = stomp.AckClient
+	// Each call is acknowledged after its final (possibly error) response is sent, NACKed only if the response cannot be sent
+	AckClientIndividual = stomp.AckClientIndividual
+)
+
 // Fancy hardcoded internal queue names here and "just working" constants
 func (l *StompListener) subscribe() error {
-	Id := fmt.Sprintf("actions.%d.%s", l.HTTPClient.Org.ID, l.MessageDestination)
-	sub, err := l.Conn.Subscribe(
-		Id,
-		stomp.AckAuto,
-		stomp.SubscribeOpt.Header("activemq.prefetchSize", "50"),
-		stomp.SubscribeOpt.Id(Id),
-	)
-	if err != nil {
-		return err
+	if len(l.routes) == 0 {
+		l.routes = []*route{{destination: l.MessageDestination}}
+	}
+	for _, r := range l.routes {
+		Id := fmt.Sprintf("actions.%d.%s", l.HTTPClient.Org.ID, r.destination)
+		sub, err := l.Conn.Subscribe(
+			Id,
+			l.AckMode,
+			stomp.SubscribeOpt.Header("activemq.prefetchSize", fmt.Sprint(l.prefetchSize())),
+			stomp.SubscribeOpt.Id(Id),
+		)
+		if err != nil {
+			return err
+		}
+		r.sub = sub
 	}
-	l.Subscription = sub
+	l.Subscription = l.routes[0].sub
 	return nil
 }
 
+// Explicit prefetch size, or the concurrency limit so that the broker does not push more calls than can be run
+func (l *StompListener) prefetchSize() int {
+	switch {
+	case l.PrefetchSize > 0:
+		return l.PrefetchSize
+	case l.MaxConcurrency > 0:
+		return l.MaxConcurrency
+	}
+	return DefaultPrefetchSize
+}
+
 // Calling handlers one-by-one, responding with updated run statuses and result as handlers suggest (JSON)
-func (l *StompListener) handleFunc(functions ...FunctionCallHandler) ProcessFunc {
+func (l *StompListener) handleFunc(functions ...ContextHandler) ProcessFunc {
 	return func(msg *stomp.Message) error {
+		completed := false
+		// deferred so that the message is rejected on any early return
+		defer func() { l.settle(msg, completed) }()
 		fc, err := parseFunctionMessage(msg.Body)
+		if err != nil {
+			// redelivery of a malformed call would fail the same way, so it is answered with an error instead
+			l.Logger.Error("Failed to parse function call", slog.Any("error", err))
+			body, _ := l.encodeResponse(nil, l.errorResponse(nil, err))
+			if err := l.sendFunctionResponse(msg, body); err != nil {
+				return err
+			}
+			l.Metrics.Failed.Add(1)
+			completed = true
+			return nil
+		}
+		defer l.track(msg, fc)()
+		leave, err := l.pool.enter(l.callContext(), fc.Function.Name)
 		if err != nil {
 			return err
 		}
+		defer leave()
+		ctx, cancel := l.functionContext(fc.Function.Name)
+		defer cancel()
+		reporter := newStatusReporter(l, msg)
+		defer reporter.close()
+		ctx = context.WithValue(ctx, statusReporterKey{}, reporter)
+		l.Metrics.Received.Add(1)
+		failed, answered := false, false
+		defer func() {
+			if failed {
+				l.Metrics.Failed.Add(1)
+			} else if completed {
+				l.Metrics.Succeeded.Add(1)
+			}
+		}()
 		for _, f := range functions {
-			fr, err := f(fc)
+			fr, err := l.invoke(ctx, f, fc)
+			if err != nil {
+				failed = true
+				l.Logger.Error("Function call failed",
+					slog.String("function_name", fc.Function.Name),
+					slog.Any("error", err))
+				fr = l.errorResponse(fc, err)
+			}
 			// no-op handler
 			if fr == nil {
 				continue
 			}
-			if err != nil {
+			body, encodeErr := l.encodeResponse(fc, fr)
+			if encodeErr != nil {
+				// reported as a failure instead of the result that cannot be sent
+				failed, err = true, encodeErr
+				l.Logger.Error("Function response encoding failed",
+					slog.String("function_name", fc.Function.Name),
+					slog.Any("error", encodeErr))
+			}
+			if fr.Complete || encodeErr != nil {
+				reporter.close()
+			}
+			if err := l.sendFunctionResponse(msg, body); err != nil {
 				return err
 			}
-			body, err := json.Marshal(fr)
+			// the call is over for SOAR once a failure is reported, the rest of the chain is skipped
 			if err != nil {
-				return err
+				answered = true
+				break
 			}
+			answered = answered || fr.Complete
+		}
+		if !answered {
+			// SOAR waits for a complete response, so a chain that never gives one is reported as failed
+			failed = true
+			err := errors.New("Function call ended without a complete response")
+			l.Logger.Error("Function call failed",
+				slog.String("function_name", fc.Function.Name),
+				slog.Any("error", err))
+			reporter.close()
+			body, _ := l.encodeResponse(fc, l.errorResponse(fc, err))
 			if err := l.sendFunctionResponse(msg, body); err != nil {
 				return err
 			}
 		}
+		completed = true
 		return nil
 	}
 }
 
+// Context of a single call, derived from the listener one and limited by the function timeout
+func (l *StompListener) functionContext(name string) (context.Context, context.CancelFunc) {
+	timeout, ok := l.FunctionTimeouts[name]
+	if !ok {
+		timeout = l.Timeout
+	}
+	if timeout > 0 {
+		return context.WithTimeout(l.callContext(), timeout)
+	}
+	return context.WithCancel(l.callContext())
+}
+
+// Parent of call contexts, outlives Ctx-independent graceful shutdown until its deadline
+func (l *StompListener) callContext() context.Context {
+	if l.callCtx == nil {
+		return l.Ctx
+	}
+	return l.callCtx
+}
+
+// Maps a handler error to the response reported to SOAR, ErrorResponse unless ErrorMapper is set
+// or it panics
+func (l *StompListener) errorResponse(fc *structures.FunctionCall, err error) (fr *structures.FuncResponse) {
+	if l.ErrorMapper != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				l.Metrics.Panicked.Add(1)
+				l.Logger.Error("Error mapper panicked",
+					slog.Any("panic", r),
+					slog.String("stack", string(debug.Stack())))
+				fr = ErrorResponse(fc, err)
+			}
+		}()
+		if fr := l.ErrorMapper(fc, err); fr != nil {
+			return fr
+		}
+	}
+	return ErrorResponse(fc, err)
+}
+
+// Encodes a handler response. A response that cannot be encoded is replaced with an error response,
+// the encoding error is returned along with it
+func (l *StompListener) encodeResponse(fc *structures.FunctionCall, fr *structures.FuncResponse) ([]byte, error) {
+	body, err := json.Marshal(fr)
+	if err == nil {
+		return body, nil
+	}
+	err = fmt.Errorf("Cannot encode function response: %w", err)
+	if body, mapErr := json.Marshal(l.errorResponse(fc, err)); mapErr == nil {
+		return body, err
+	}
+	// ErrorMapper produced an unencodable response as well
+	body, _ = json.Marshal(ErrorResponse(fc, err))
+	return body, err
+}
+
+// Encodes and sends a status update to a message
+func (l *StompListener) respond(msg *stomp.Message, fr *structures.FuncResponse) error {
+	body, err := json.Marshal(fr)
+	if err != nil {
+		return err
+	}
+	return l.sendFunctionResponse(msg, body)
+}
+
+// Acknowledges the message once its final response is sent or rejects it for redelivery, no-op in auto mode.
+// Goes through the connection the message came from, as the listener may have reconnected since
+func (l *StompListener) settle(msg *stomp.Message, completed bool) {
+	if !msg.ShouldAck() {
+		return
+	}
+	var err error
+	if completed {
+		err = msg.Conn.Ack(msg)
+	} else {
+		err = msg.Conn.Nack(msg)
+	}
+	if err != nil {
+		l.Logger.Error("Failed to settle STOMP message",
+			slog.Bool("ack", completed),
+			slog.Any("error", err))
+	}
+}
+
 // Responds, specifing the message it responds to with the bytes provided
 func (l *StompListener) sendFunctionResponse(msg *stomp.Message, body []byte) error {
 	correlationID := msg.Header.Get("correlation-id")
-	return l.Conn.Send(
-		fmt.Sprintf("acks.%d.%s", l.HTTPClient.Org.ID, l.MessageDestination),
+	// responses go to acks.<org>.<md> of the actions.<org>.<md> queue the message came from
+	queue := "acks." + strings.TrimPrefix(msg.Subscription.Destination(), "actions.")
+	// same connection as the one the message is acknowledged on, so that the response always precedes the ACK
+	return msg.Conn.Send(
+		queue,
 		"application/json",
 		body,
 		stomp.SendOpt.Header("correlation-id", correlationID),
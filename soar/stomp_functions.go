@@ -81,20 +81,3 @@ func LoadInputs[Input any](fc *structures.FunctionCall) (*Input, error) {
 	}
 	return &ret, nil
 }
-
-// Not implemented
-type FunctionLookup struct {
-	mapping map[string]FunctionCallHandler
-}
-
-func (l *FunctionLookup) Register(name string, handler FunctionCallHandler) {
-	l.mapping[name] = handler
-}
-
-func (l *FunctionLookup) Handler(c *structures.FunctionCall) (*structures.FuncResponse, error) {
-	f, ok := l.mapping[c.Function.Name]
-	if !ok {
-		return nil, fmt.Errorf("Got a call with unregistered function name: %s", c.Function.Name)
-	}
-	return f(c)
-}
@@ -0,0 +1,51 @@
+package soar
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Counters of function calls handled by a listener, safe for concurrent reads
+type Metrics struct {
+	// Calls parsed and dispatched to handlers
+	Received atomic.Int64
+	// Calls completed without handler errors
+	Succeeded atomic.Int64
+	// Calls reported to SOAR as failed, panics included
+	Failed atomic.Int64
+	// Calls during which a handler or the ErrorMapper panicked
+	Panicked atomic.Int64
+}
+
+// Handler panic converted into an error, so that it can be reported as any other failure
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Function handler panicked: %v", e.Value)
+}
+
+// Calls a single handler, recovering a panic into PanicError
+func (l *StompListener) invoke(ctx context.Context, f ContextHandler, fc *structures.FunctionCall) (fr *structures.FuncResponse, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		perr := &PanicError{Value: r, Stack: debug.Stack()}
+		l.Metrics.Panicked.Add(1)
+		l.Logger.Error("Function handler panicked",
+			slog.String("function_name", fc.Function.Name),
+			slog.Any("panic", r),
+			slog.String("stack", string(perr.Stack)))
+		fr, err = nil, perr
+	}()
+	return f(ctx, fc)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 type StompOption func(*StompListener) error
@@ -35,7 +36,8 @@ func (StompOpts) Context(ctx context.Context) func(*StompListener) error {
 	}
 }
 
-// Whether to trust the self-signed certificate or not
+// Whether to trust the self-signed certificate or not, on top of the HTTPClient TLS configuration
+// which is reused for STOMP (so NewHTTPClient insecure flag applies to both)
 func (StompOpts) Insecure(insecure bool) func(*StompListener) error {
 	return func(l *StompListener) error {
 		l.Insecure = insecure
@@ -50,3 +52,155 @@ func (StompOpts) Logger(logger *slog.Logger) func(*StompListener) error {
 		return nil
 	}
 }
+
+// Backoff used to reconnect after the connection to SOAR is lost, DefaultBackoff by default
+func (StompOpts) Reconnect(b Backoff) func(*StompListener) error {
+	return func(l *StompListener) error {
+		l.Reconnect = &b
+		return nil
+	}
+}
+
+// Makes the listener stop (and close Done) on the first connection loss
+func (StompOpts) DisableReconnect() func(*StompListener) error {
+	return func(l *StompListener) error {
+		l.Reconnect = nil
+		return nil
+	}
+}
+
+// Callback receiving listener lifecycle events (connected, disconnected, retrying)
+func (StompOpts) OnEvent(f func(ListenerEvent)) func(*StompListener) error {
+	return func(l *StompListener) error {
+		l.OnEvent = f
+		return nil
+	}
+}
+
+// STOMP heart-beat intervals, send is how often the listener pings the broker
+// and recv is how often the broker is expected to ping back, 0 disables the direction.
+// Missed heart-beats from the broker are treated as a connection loss
+func (StompOpts) HeartBeat(send, recv time.Duration) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if send < 0 || recv < 0 {
+			return fmt.Errorf("Negative heart-beat interval: %v, %v", send, recv)
+		}
+		l.HeartBeatSend = send
+		l.HeartBeatRecv = recv
+		return nil
+	}
+}
+
+// Acknowledgement mode of the function queue, AckAuto by default.
+// In client modes a call is ACKed after its final response is sent, failed handlers included (SOAR gets an error response),
+// and NACKed only if the response cannot be sent
+func (StompOpts) AckMode(mode AckMode) func(*StompListener) error {
+	return func(l *StompListener) error {
+		switch mode {
+		case AckAuto, AckClient, AckClientIndividual:
+			l.AckMode = mode
+			return nil
+		}
+		return fmt.Errorf("Unknown STOMP ack mode: %d", mode)
+	}
+}
+
+// Maximum number of function calls handled at once, further calls wait in the broker queue.
+// Unless set explicitly, prefetch size follows this limit
+func (StompOpts) MaxConcurrency(n int) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if n < 0 {
+			return fmt.Errorf("Negative concurrency limit: %d", n)
+		}
+		l.MaxConcurrency = n
+		return nil
+	}
+}
+
+// Maximum number of concurrent calls of a single function (by function api name),
+// a call waiting for its function slot still counts against MaxConcurrency
+func (StompOpts) FunctionConcurrency(name string, n int) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if n < 0 {
+			return fmt.Errorf("Negative concurrency limit for %s: %d", name, n)
+		}
+		if l.FunctionConcurrency == nil {
+			l.FunctionConcurrency = make(map[string]int)
+		}
+		l.FunctionConcurrency[name] = n
+		return nil
+	}
+}
+
+// Number of unacknowledged calls the broker may push to the listener (activemq.prefetchSize), 50 by default
+func (StompOpts) PrefetchSize(n int) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if n < 0 {
+			return fmt.Errorf("Negative prefetch size: %d", n)
+		}
+		l.PrefetchSize = n
+		return nil
+	}
+}
+
+// Custom conversion of handler errors into responses reported to SOAR, ErrorResponse is used if nil is returned
+// or the mapper panics
+func (StompOpts) ErrorMapper(m ErrorMapper) func(*StompListener) error {
+	return func(l *StompListener) error {
+		l.ErrorMapper = m
+		return nil
+	}
+}
+
+// Deadline of every function call, handlers observe it via their context. 0 (default) means no deadline
+func (StompOpts) Timeout(d time.Duration) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if d < 0 {
+			return fmt.Errorf("Negative function timeout: %v", d)
+		}
+		l.Timeout = d
+		return nil
+	}
+}
+
+// Deadline of a single function calls (by function api name), overrides Timeout
+func (StompOpts) FunctionTimeout(name string, d time.Duration) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if d < 0 {
+			return fmt.Errorf("Negative timeout for %s: %v", name, d)
+		}
+		if l.FunctionTimeouts == nil {
+			l.FunctionTimeouts = make(map[string]time.Duration)
+		}
+		l.FunctionTimeouts[name] = d
+		return nil
+	}
+}
+
+// Minimal interval between status updates of a single call sent via StatusReporter, 1 second by default
+func (StompOpts) StatusInterval(d time.Duration) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if d < 0 {
+			return fmt.Errorf("Negative status interval: %v", d)
+		}
+		l.StatusInterval = d
+		return nil
+	}
+}
+
+// Additional message destination served over the same connection by its own handlers
+func (StompOpts) Destination(md string, f ...ContextHandler) func(*StompListener) error {
+	return func(l *StompListener) error {
+		if l.Destinations == nil {
+			l.Destinations = make(map[string][]ContextHandler)
+		}
+		if _, ok := l.Destinations[md]; ok {
+			return fmt.Errorf("Message destination %s is configured twice", md)
+		}
+		if len(f) == 0 {
+			return fmt.Errorf("No handlers are given for message destination %s", md)
+		}
+		l.Destinations[md] = f
+		return nil
+	}
+}
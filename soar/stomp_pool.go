@@ -0,0 +1,63 @@
+package soar
+
+import (
+	"context"
+)
+
+// Default activemq.prefetchSize of the function queue subscription
+const DefaultPrefetchSize = 50
+
+// Semaphores limiting concurrent function invocations of a listener, nil pool has no limits
+type workerPool struct {
+	global      chan struct{}
+	perFunction map[string]chan struct{}
+}
+
+func newWorkerPool(max int, perFunction map[string]int) *workerPool {
+	if max <= 0 && len(perFunction) == 0 {
+		return nil
+	}
+	p := &workerPool{perFunction: make(map[string]chan struct{}, len(perFunction))}
+	if max > 0 {
+		p.global = make(chan struct{}, max)
+	}
+	for name, limit := range perFunction {
+		if limit > 0 {
+			p.perFunction[name] = make(chan struct{}, limit)
+		}
+	}
+	return p
+}
+
+// Channel to send to in order to take a global slot, nil when the number of invocations is unbounded
+func (p *workerPool) slots() chan struct{} {
+	if p == nil {
+		return nil
+	}
+	return p.global
+}
+
+// Frees a global slot taken via slots
+func (p *workerPool) release() {
+	if p == nil || p.global == nil {
+		return
+	}
+	<-p.global
+}
+
+// Waits for a slot of a function with a limit, the returned func frees it
+func (p *workerPool) enter(ctx context.Context, name string) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+	sem, ok := p.perFunction[name]
+	if !ok {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,150 @@
+package soar
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential backoff parameters used between reconnection attempts
+type Backoff struct {
+	// Delay before the first attempt
+	Initial time.Duration
+	// Upper bound of a delay, jitter is applied after capping
+	Max time.Duration
+	// Growth factor of a delay per attempt, 2 if not set
+	Multiplier float64
+	// Fraction of a delay (0..1) randomly added or subtracted
+	Jitter float64
+	// Number of consecutive failed attempts before giving up, 0 means retry forever
+	MaxRetries int
+}
+
+// Backoff used by NewStompListener unless overridden
+var DefaultBackoff = Backoff{
+	Initial:    time.Second,
+	Max:        time.Minute,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// Delay before the given reconnection attempt, attempts are counted from 1
+func (b Backoff) Delay(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	d := float64(b.Initial) * math.Pow(multiplier, float64(max(attempt-1, 0)))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// Kind of a listener lifecycle event
+type ListenerEventType int
+
+const (
+	// Connected and subscribed to the function queue
+	EventConnected ListenerEventType = iota
+	// Connection or subscription is lost
+	EventDisconnected
+	// Waiting before the next reconnection attempt
+	EventRetrying
+)
+
+func (t ListenerEventType) String() string {
+	switch t {
+	case EventConnected:
+		return "connected"
+	case EventDisconnected:
+		return "disconnected"
+	case EventRetrying:
+		return "retrying"
+	}
+	return fmt.Sprintf("ListenerEventType(%d)", int(t))
+}
+
+// Lifecycle event reported to StompListener.OnEvent
+type ListenerEvent struct {
+	Type ListenerEventType
+	// Reconnection attempt number, set for EventRetrying
+	Attempt int
+	// Delay before the attempt, set for EventRetrying
+	Delay time.Duration
+	// Cause of a disconnect or of the previous failed attempt
+	Err error
+}
+
+func (l *StompListener) emit(e ListenerEvent) {
+	if l.OnEvent != nil {
+		l.OnEvent(e)
+	}
+}
+
+// Keeps the listener running, reconnecting with backoff whenever the connection is lost.
+// Returns nil upon context cancellation or the last error once reconnection is given up
+func (l *StompListener) supervise() error {
+	for {
+		err := l.stompLoop()
+		if l.Ctx.Err() != nil || l.isStopping() {
+			return nil
+		}
+		l.Logger.Warn("STOMP connection lost", slog.Any("error", err))
+		l.emit(ListenerEvent{Type: EventDisconnected, Err: err})
+		if l.Reconnect == nil {
+			return err
+		}
+		if err := l.reconnect(err); err != nil {
+			if l.Ctx.Err() != nil || l.isStopping() {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Retries connect until it succeeds, the context is cancelled or retries are exhausted
+func (l *StompListener) reconnect(cause error) error {
+	for attempt := 1; ; attempt++ {
+		if l.Reconnect.MaxRetries > 0 && attempt > l.Reconnect.MaxRetries {
+			return fmt.Errorf("STOMP reconnection failed after %d attempts: %w", l.Reconnect.MaxRetries, cause)
+		}
+		delay := l.Reconnect.Delay(attempt)
+		l.Logger.Info("STOMP reconnecting",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay))
+		l.emit(ListenerEvent{Type: EventRetrying, Attempt: attempt, Delay: delay, Err: cause})
+		timer := time.NewTimer(delay)
+		select {
+		case <-l.Ctx.Done():
+			timer.Stop()
+			return l.Ctx.Err()
+		case <-l.stopping:
+			timer.Stop()
+			return errStopping
+		case <-timer.C:
+		}
+		if cause = l.connect(); cause == nil {
+			return nil
+		}
+	}
+}
+
+var errStopping = errors.New("STOMP listener is shutting down")
+
+// Whether Shutdown was called
+func (l *StompListener) isStopping() bool {
+	select {
+	case <-l.stopping:
+		return true
+	default:
+		return false
+	}
+}
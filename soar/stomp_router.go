@@ -0,0 +1,143 @@
+package soar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Wraps a ContextHandler with extra behaviour (logging, input checks, status updates, etc.)
+type Middleware func(ContextHandler) ContextHandler
+
+// Dispatcher of function calls sharing a message destination, routes by function api name or UUID
+type FunctionLookup struct {
+	mu         sync.RWMutex
+	mapping    map[string]ContextHandler
+	uuids      map[string]ContextHandler
+	middleware []Middleware
+	fallback   ContextHandler
+}
+
+// Creates an empty dispatcher, calls of unregistered functions are answered with an error
+func NewFunctionLookup() *FunctionLookup {
+	return &FunctionLookup{
+		mapping: make(map[string]ContextHandler),
+		uuids:   make(map[string]ContextHandler),
+	}
+}
+
+// Registers a handler by function api name, middleware is applied in order (first is outermost).
+// Plain FunctionCallHandler is registered via WithContext
+func (l *FunctionLookup) Register(name string, handler ContextHandler, mw ...Middleware) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mapping[name] = chain(handler, mw)
+}
+
+// Registers a handler by function UUID, which takes precedence over the name
+func (l *FunctionLookup) RegisterUUID(uuid string, handler ContextHandler, mw ...Middleware) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.uuids[uuid] = chain(handler, mw)
+}
+
+// Middleware applied to every dispatched call (fallback included), outside of per-function middleware
+func (l *FunctionLookup) Use(mw ...Middleware) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.middleware = append(l.middleware, mw...)
+}
+
+// Handler for calls of unregistered functions, replacing the default error
+func (l *FunctionLookup) Fallback(handler ContextHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fallback = handler
+}
+
+// Api names of registered functions, sorted
+func (l *FunctionLookup) Functions() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	names := make([]string, 0, len(l.mapping))
+	for name := range l.mapping {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// FunctionCallHandler dispatching a call to the registered function, to be passed to StompListener.Listen
+func (l *FunctionLookup) Handler(c *structures.FunctionCall) (*structures.FuncResponse, error) {
+	return l.HandleContext(context.Background(), c)
+}
+
+// ContextHandler dispatching a call to the registered function, to be passed to StompListener.ListenContext
+func (l *FunctionLookup) HandleContext(ctx context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+	l.mu.RLock()
+	f, ok := l.uuids[functionUUID(c)]
+	if !ok {
+		f, ok = l.mapping[c.Function.Name]
+	}
+	if !ok {
+		f = l.fallback
+	}
+	mw := l.middleware
+	l.mu.RUnlock()
+	if f == nil {
+		f = unregisteredFunction
+	}
+	return chain(f, mw)(ctx, c)
+}
+
+// Checks registered handlers against functions bound to the message destination (see HTTPClient.GetFunctions),
+// reporting handlers for functions missing from it and its functions left without a handler
+func (l *FunctionLookup) Validate(functions []structures.FunctionDefinition, destinationID int) error {
+	bound := make(map[string]bool)
+	var errs []error
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, fd := range functions {
+		if fd.DestinationHandle != destinationID {
+			continue
+		}
+		bound[fd.Name] = true
+		_, byName := l.mapping[fd.Name]
+		_, byUUID := l.uuids[fd.UUID]
+		if !byName && !byUUID && l.fallback == nil {
+			errs = append(errs, fmt.Errorf("Function %s has no registered handler", fd.Name))
+		}
+	}
+	var missing []string
+	for name := range l.mapping {
+		if !bound[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		errs = append(errs, fmt.Errorf("Functions are not bound to the message destination: %s", strings.Join(missing, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+func unregisteredFunction(_ context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+	return nil, fmt.Errorf("Got a call with unregistered function name: %s", c.Function.Name)
+}
+
+func functionUUID(c *structures.FunctionCall) string {
+	uuid, _ := c.Function.UUID.(string)
+	return uuid
+}
+
+func chain(handler ContextHandler, mw []Middleware) ContextHandler {
+	for _, m := range slices.Backward(mw) {
+		handler = m(handler)
+	}
+	return handler
+}
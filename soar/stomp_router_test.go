@@ -0,0 +1,99 @@
+package soar
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+func respondWith(message string) ContextHandler {
+	return func(context.Context, *structures.FunctionCall) (*structures.FuncResponse, error) {
+		return &structures.FuncResponse{Message: message}, nil
+	}
+}
+
+func appendMessage(suffix string) Middleware {
+	return func(next ContextHandler) ContextHandler {
+		return func(ctx context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+			fr, err := next(ctx, c)
+			if fr != nil {
+				fr.Message += suffix
+			}
+			return fr, err
+		}
+	}
+}
+
+func TestFunctionLookupDispatch(t *testing.T) {
+	lookup := NewFunctionLookup()
+	lookup.Use(appendMessage(" global"))
+	lookup.Register("first", respondWith("first"), appendMessage(" a"), appendMessage(" b"))
+	lookup.Register("second", respondWith("second"))
+	lookup.RegisterUUID("uuid-2", respondWith("by uuid"))
+
+	tests := []struct {
+		name     string
+		function structures.Function
+		expected string
+	}{
+		{"by name with middleware", structures.Function{Name: "first"}, "first b a global"},
+		{"by name", structures.Function{Name: "second"}, "second global"},
+		{"uuid precedence", structures.Function{Name: "second", UUID: "uuid-2"}, "by uuid global"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fr, err := lookup.Handler(&structures.FunctionCall{Function: tt.function})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fr.Message != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, fr.Message)
+			}
+		})
+	}
+
+	if names := lookup.Functions(); strings.Join(names, ",") != "first,second" {
+		t.Errorf("Unexpected registered functions: %v", names)
+	}
+}
+
+func TestFunctionLookupFallback(t *testing.T) {
+	lookup := NewFunctionLookup()
+	if _, err := lookup.Handler(&structures.FunctionCall{Function: structures.Function{Name: "unknown"}}); err == nil {
+		t.Error("Expected error for unregistered function")
+	}
+	lookup.Fallback(respondWith("fallback"))
+	fr, err := lookup.Handler(&structures.FunctionCall{Function: structures.Function{Name: "unknown"}})
+	if err != nil || fr.Message != "fallback" {
+		t.Errorf("Expected fallback response, got %v, %v", fr, err)
+	}
+}
+
+func TestFunctionLookupValidate(t *testing.T) {
+	lookup := NewFunctionLookup()
+	lookup.Register("bound", respondWith("bound"))
+	lookup.Register("elsewhere", respondWith("elsewhere"))
+	functions := []structures.FunctionDefinition{
+		{Name: "bound", DestinationHandle: 7},
+		{Name: "unhandled", DestinationHandle: 7},
+		{Name: "elsewhere", DestinationHandle: 8},
+	}
+
+	err := lookup.Validate(functions, 7)
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, expected := range []string{"unhandled has no registered handler", "not bound to the message destination: elsewhere"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %q", expected, err)
+		}
+	}
+	valid := NewFunctionLookup()
+	valid.Register("bound", respondWith("bound"))
+	valid.Fallback(respondWith("fallback"))
+	if err := valid.Validate(functions, 7); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package soar
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+	"github.com/go-stomp/stomp/v3"
+)
+
+// Function call that was still running when a graceful shutdown deadline passed
+type AbortedCall struct {
+	CorrelationID string
+	Call          *structures.FunctionCall
+}
+
+// Gracefully stops the listener: no new calls are read, in-flight calls are awaited and their responses sent,
+// then the listener unsubscribes and disconnects. Once ctx is done, contexts of the calls still running are
+// cancelled and those calls are returned along with the ctx error. Unread calls stay with the broker
+// only in client ack modes, in AckAuto mode they are already acknowledged. A listener still connecting
+// disconnects and fails to start, a listener never started will not start afterwards
+func (l *StompListener) Shutdown(ctx context.Context) ([]AbortedCall, error) {
+	l.init()
+	l.startMu.Lock()
+	l.stopOnce.Do(func() {
+		l.Logger.Info("STOMP is shutting down gracefully")
+		close(l.stopping)
+	})
+	starting := l.starting
+	l.startMu.Unlock()
+	// a listener that was never started will not start anymore
+	if !starting {
+		return nil, nil
+	}
+	select {
+	case <-l.started:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if l.startErr != nil {
+		return nil, nil
+	}
+	select {
+	case <-l.Done:
+		return nil, nil
+	case <-ctx.Done():
+	}
+	var aborted []AbortedCall
+	l.inflight.Range(func(_, v any) bool {
+		aborted = append(aborted, v.(AbortedCall))
+		return true
+	})
+	for _, a := range aborted {
+		l.Logger.Warn("Aborting function call",
+			slog.String("correlation_id", a.CorrelationID),
+			slog.String("function_name", a.Call.Function.Name))
+	}
+	l.abort()
+	<-l.Done
+	return aborted, ctx.Err()
+}
+
+// Waits for in-flight calls to finish or to be aborted by Shutdown
+func (l *StompListener) drain() {
+	l.Logger.Info("STOMP stopped reading calls, waiting for in-flight ones")
+	drained := make(chan struct{})
+	go func() {
+		l.calls.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-l.callCtx.Done():
+	}
+}
+
+// Registers a call as in-flight until the returned func is called
+func (l *StompListener) track(msg *stomp.Message, fc *structures.FunctionCall) func() {
+	l.inflight.Store(msg, AbortedCall{CorrelationID: msg.Header.Get("correlation-id"), Call: fc})
+	return func() { l.inflight.Delete(msg) }
+}
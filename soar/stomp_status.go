@@ -0,0 +1,109 @@
+package soar
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+	"github.com/go-stomp/stomp/v3"
+)
+
+// Interval between status updates of a call used by NewStompListener
+const DefaultStatusInterval = time.Second
+
+// Sends intermediate status messages of a single call, shown in the playbook action status.
+// Updates more frequent than the listener StatusInterval are coalesced, only the latest one is sent.
+// A nil reporter (outside of a listener call) discards updates
+type StatusReporter struct {
+	mu       sync.Mutex
+	l        *StompListener
+	msg      *stomp.Message
+	interval time.Duration
+	lastSent time.Time
+	pending  string
+	timer    *time.Timer
+	closed   bool
+}
+
+type statusReporterKey struct{}
+
+// Reporter of the call the context belongs to, nil if the context does not come from a listener
+func StatusReporterFrom(ctx context.Context) *StatusReporter {
+	r, _ := ctx.Value(statusReporterKey{}).(*StatusReporter)
+	return r
+}
+
+// Shorthand for StatusReporterFrom(ctx).Status(msg)
+func ReportStatus(ctx context.Context, msg string) {
+	StatusReporterFrom(ctx).Status(msg)
+}
+
+// Sends a status update unless the previous one was sent less than StatusInterval ago,
+// in which case it is delayed and may be replaced by a newer one
+func (r *StatusReporter) Status(msg string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	wait := r.interval - time.Since(r.lastSent)
+	if wait <= 0 {
+		r.send(msg)
+		return
+	}
+	r.pending = msg
+	if r.timer == nil {
+		r.timer = time.AfterFunc(wait, r.flush)
+	}
+}
+
+// Formatted Status
+func (r *StatusReporter) Statusf(format string, v ...any) {
+	r.Status(fmt.Sprintf(format, v...))
+}
+
+func newStatusReporter(l *StompListener, msg *stomp.Message) *StatusReporter {
+	return &StatusReporter{l: l, msg: msg, interval: l.StatusInterval}
+}
+
+// Sends the delayed update
+func (r *StatusReporter) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timer = nil
+	if r.closed {
+		return
+	}
+	r.send(r.pending)
+}
+
+// Must be called with the lock held
+func (r *StatusReporter) send(status string) {
+	r.lastSent = time.Now()
+	r.pending = ""
+	err := r.l.respond(r.msg, &structures.FuncResponse{
+		MessageType: 0,
+		Message:     status,
+		Complete:    false,
+	})
+	if err != nil {
+		r.l.Logger.Warn("Failed to send function status", slog.Any("error", err))
+	}
+}
+
+// Stops sending updates, so that none can follow the final response
+func (r *StatusReporter) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
@@ -3,12 +3,20 @@ package soar
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
 	"github.com/chmele/ibm-soar/soar/structures"
 )
 
@@ -19,10 +27,20 @@ type FakeConn struct {
 	WriteData bytes.Buffer
 
 	WriteNotify chan struct{} // Signal channel
+	AutoReceipt bool          // Answer frames requesting a receipt, like a broker would
 	closed      bool
+
+	mu   sync.Mutex
+	cond *sync.Cond
 }
 
+// Blocks until there is data to read or the connection is closed, like a real socket
 func (f *FakeConn) Read(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.ReadData) == 0 && !f.closed {
+		f.wait()
+	}
 	if len(f.ReadData) == 0 {
 		return 0, io.EOF
 	}
@@ -31,8 +49,27 @@ func (f *FakeConn) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
+// Appends data to be read by the client, as if it was sent by the broker
+func (f *FakeConn) Feed(data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ReadData = append(f.ReadData, data...)
+	f.broadcast()
+}
+
 func (f *FakeConn) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return 0, net.ErrClosed
+	}
 	n, err = f.WriteData.Write(p)
+	if _, rest, ok := bytes.Cut(p, []byte("\nreceipt:")); ok && f.AutoReceipt {
+		id, _, _ := bytes.Cut(rest, []byte("\n"))
+		f.ReadData = fmt.Appendf(f.ReadData, "RECEIPT\nreceipt-id:%s\n\n\x00", id)
+		f.broadcast()
+	}
+	f.mu.Unlock()
 	if f.WriteNotify != nil {
 		// non-blocking notify
 		select {
@@ -44,10 +81,33 @@ func (f *FakeConn) Write(p []byte) (n int, err error) {
 }
 
 func (f *FakeConn) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.closed = true
+	f.broadcast()
 	return nil
 }
 
+// Written data snapshot, safe to call while the client is writing
+func (f *FakeConn) Written() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return bytes.Clone(f.WriteData.Bytes())
+}
+
+func (f *FakeConn) wait() {
+	if f.cond == nil {
+		f.cond = sync.NewCond(&f.mu)
+	}
+	f.cond.Wait()
+}
+
+func (f *FakeConn) broadcast() {
+	if f.cond != nil {
+		f.cond.Broadcast()
+	}
+}
+
 type dummyAddr struct {
 	addr string
 }
@@ -85,6 +145,56 @@ func CompareBytes(t *testing.T, expected, actual []byte) {
 
 }
 
+const testSubscribe = "SUBSCRIBE\ndestination:actions.123.unit-test\n"
+
+// Waits for the client to write a payload containing the pattern
+func WaitWritten(t *testing.T, conn *FakeConn, pattern string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !bytes.Contains(conn.Written(), []byte(pattern)) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %q to be written, got: %q", pattern, conn.Written())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Listener connected to the given fake broker connections one after another, reconnects are disabled
+func NewTestListener(conns ...*FakeConn) *StompListener {
+	dialed := 0
+	return &StompListener{
+		HTTPClient: &HTTPClient{
+			Hostname:  "test-host",
+			KeyId:     "test-id",
+			KeySecret: "test-secret",
+			Org:       &structures.Org{ID: 123},
+		},
+		StompPort:          "65001",
+		Ctx:                context.Background(),
+		Done:               make(chan struct{}),
+		MessageDestination: "unit-test",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		dial: func() (net.Conn, error) {
+			if dialed == len(conns) {
+				return nil, errors.New("connection refused")
+			}
+			dialed++
+			return conns[dialed-1], nil
+		},
+	}
+}
+
+// Broker connection that accepts the STOMP handshake
+func NewTestConn() *FakeConn {
+	return &FakeConn{ReadData: []byte("CONNECTED\nversion:1.2\n\n\x00")}
+}
+
+// MESSAGE frame carrying a function call to the listener subscription
+func FunctionMessage(id, name string) []byte {
+	return fmt.Appendf(nil, "MESSAGE\ndestination:actions.123.unit-test\nsubscription:actions.123.unit-test\n"+
+		"message-id:%s\nack:%s\ncorrelation-id:%s\n\n{\"function\":{\"name\":%q}}\x00", id, id, id, name)
+}
+
 func TestStompConnection(t *testing.T) {
 	client := &HTTPClient{
 		Hostname:  "test-host",
@@ -175,3 +285,613 @@ func TestStompSubscription(t *testing.T) {
 	expected := []byte("SUBSCRIBE\ndestination:actions.123.unit-test\nack:auto\nactivemq.prefetchSize:50\nid:actions.123.unit-test\n\n\x00")
 	CompareBytes(t, expected, actual)
 }
+
+func TestStompReconnect(t *testing.T) {
+	conns := []*FakeConn{NewTestConn(), NewTestConn()}
+	events := make(chan ListenerEvent, 16)
+
+	listener := NewTestListener(conns...)
+	listener.Reconnect = &Backoff{Initial: 10 * time.Millisecond, MaxRetries: 1}
+	listener.OnEvent = func(e ListenerEvent) { events <- e }
+	if err := listener.Listen(LoggingResponse); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	expectEvent := func(expected ListenerEventType) ListenerEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Type != expected {
+				t.Fatalf("Expected %s event, got %s (%v)", expected, e.Type, e.Err)
+			}
+			return e
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s event", expected)
+		}
+		return ListenerEvent{}
+	}
+
+	expectEvent(EventConnected)
+	WaitWritten(t, conns[0], testSubscribe)
+	conns[0].Close()
+	expectEvent(EventDisconnected)
+	if e := expectEvent(EventRetrying); e.Attempt != 1 {
+		t.Errorf("Expected first attempt, got %d", e.Attempt)
+	}
+	expectEvent(EventConnected)
+	WaitWritten(t, conns[1], testSubscribe)
+
+	// the only retry allowed fails to dial, so the listener gives up
+	conns[1].Close()
+	expectEvent(EventDisconnected)
+	expectEvent(EventRetrying)
+	select {
+	case <-listener.Done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for listener to give up")
+	}
+	if listener.Err == nil {
+		t.Error("Expected listener error after exhausting retries")
+	}
+}
+
+func TestStompHeartBeat(t *testing.T) {
+	conn := &FakeConn{ReadData: []byte("CONNECTED\nversion:1.2\nheart-beat:50,50\n\n\x00")}
+	events := make(chan ListenerEvent, 16)
+
+	listener := NewTestListener(conn)
+	listener.OnEvent = func(e ListenerEvent) { events <- e }
+	listener.heartBeatError = 10 * time.Millisecond
+	if err := Stomp.HeartBeat(50*time.Millisecond, 50*time.Millisecond)(listener); err != nil {
+		t.Fatalf("Failed to apply option: %v", err)
+	}
+	if err := listener.Listen(LoggingResponse); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	expected := []byte("CONNECT\nhost:test-host\nheart-beat:50,50\n")
+	if !bytes.HasPrefix(conn.Written(), expected) {
+		t.Errorf("Expected heart-beat negotiation, got: %q", conn.Written())
+	}
+
+	// the broker stays silent, so the missed heart-beat must be reported as a disconnect
+	for disconnected := false; !disconnected; {
+		select {
+		case e := <-events:
+			if e.Type != EventDisconnected {
+				continue
+			}
+			if e.Err == nil {
+				t.Error("Expected disconnect cause")
+			}
+			disconnected = true
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for missed heart-beat disconnect")
+		}
+	}
+	select {
+	case <-listener.Done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for listener to stop")
+	}
+}
+
+func TestStompHeartBeatOption(t *testing.T) {
+	listener := &StompListener{}
+	if err := Stomp.HeartBeat(-time.Second, 0)(listener); err == nil {
+		t.Error("Expected error for negative heart-beat interval")
+	}
+}
+
+func TestStompClientAck(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  FunctionCallHandler
+		expected string
+	}{
+		{"completed", CompletedResponse, "ACK\nid:m1\n"},
+		{"failed", func(*structures.FunctionCall) (*structures.FuncResponse, error) {
+			return nil, errors.New("handler failed")
+		}, "ACK\nid:m1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := NewTestConn()
+			listener := NewTestListener(conn)
+			listener.AckMode = AckClientIndividual
+			if err := listener.Listen(tt.handler); err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+			WaitWritten(t, conn, "SUBSCRIBE\ndestination:actions.123.unit-test\nack:client-individual\n")
+			conn.Feed(FunctionMessage("m1", "fn"))
+			WaitWritten(t, conn, tt.expected)
+
+			written := conn.Written()
+			if response := bytes.Index(written, []byte("SEND\n")); response < 0 || response > bytes.Index(written, []byte(tt.expected)) {
+				t.Errorf("Expected the response to be sent before ACK, got: %q", written)
+			}
+			conn.Close()
+		})
+	}
+}
+
+func TestStompIncompleteChain(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	listener.AckMode = AckClientIndividual
+	noop := func(*structures.FunctionCall) (*structures.FuncResponse, error) {
+		return nil, nil
+	}
+	if err := listener.Listen(noop); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+	conn.Feed(FunctionMessage("m1", "fn"))
+	WaitWritten(t, conn, "ACK\nid:m1\n")
+
+	written := conn.Written()
+	response := bytes.Index(written, []byte(`"message":"Error occured: Function call ended without a complete response"`))
+	if response < 0 || response > bytes.Index(written, []byte("ACK\nid:m1\n")) {
+		t.Errorf("Expected an error completion before ACK, got: %q", written)
+	}
+	if failed := listener.Metrics.Failed.Load(); failed != 1 {
+		t.Errorf("Expected the call to be counted as failed, got: %d", failed)
+	}
+	conn.Close()
+}
+
+func TestStompConcurrencyLimit(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	listener.MaxConcurrency = 2
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	release := make(chan struct{})
+	started := make(chan struct{}, 5)
+	handler := func(*structures.FunctionCall) (*structures.FuncResponse, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		started <- struct{}{}
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	}
+	if err := listener.Listen(handler); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, "activemq.prefetchSize:2\n")
+	for i := range 5 {
+		conn.Feed(FunctionMessage(fmt.Sprint(i), "fn"))
+	}
+
+	for range 2 {
+		<-started
+	}
+	select {
+	case <-started:
+		t.Fatal("Expected at most 2 concurrent calls")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	for range 3 {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for queued calls")
+		}
+	}
+	if peak != 2 {
+		t.Errorf("Expected peak concurrency of 2, got %d", peak)
+	}
+	conn.Close()
+}
+
+func TestWorkerPoolFunctionLimit(t *testing.T) {
+	pool := newWorkerPool(0, map[string]int{"limited": 1})
+	leave, err := pool.enter(context.Background(), "limited")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := pool.enter(context.Background(), "other"); err != nil {
+		t.Errorf("Expected unlimited function to enter, got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.enter(ctx, "limited"); err == nil {
+		t.Error("Expected second call of a limited function to wait")
+	}
+	leave()
+	if _, err := pool.enter(context.Background(), "limited"); err != nil {
+		t.Errorf("Expected slot to be freed, got: %v", err)
+	}
+}
+
+func TestStompHandlerError(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	failing := func(c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		if c.Function.Name == "broken" {
+			return nil, errors.New("boom")
+		}
+		return nil, nil
+	}
+	if err := listener.Listen(failing, CompletedResponse); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+
+	conn.Feed(FunctionMessage("m1", "broken"))
+	WaitWritten(t, conn, `"message_type":3,"message":"Error occured: boom","complete":true`)
+	// the listener keeps serving calls after a failure
+	conn.Feed(FunctionMessage("m2", "working"))
+	WaitWritten(t, conn, "correlation-id:m2\n")
+	if bytes.Count(conn.Written(), []byte(`"message_type":3`)) != 1 {
+		t.Errorf("Expected a single error response, got: %q", conn.Written())
+	}
+	conn.Close()
+}
+
+func TestStompUnencodableResult(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	nan := func(c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		return &structures.FuncResponse{Complete: true, Results: &structures.Results{Content: math.NaN()}}, nil
+	}
+	if err := listener.Listen(nan, CompletedResponse); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+
+	conn.Feed(FunctionMessage("m1", "nan"))
+	WaitWritten(t, conn, `"message":"Error occured: Cannot encode function response: json: unsupported value: NaN"`)
+	// the connection is kept and the rest of the chain is skipped
+	conn.Feed(FunctionMessage("m2", "nan"))
+	WaitWritten(t, conn, "correlation-id:m2\n")
+	if bytes.Contains(conn.Written(), []byte("UNSUBSCRIBE")) || bytes.Contains(conn.Written(), []byte("App function completed")) {
+		t.Errorf("Unexpected frames: %q", conn.Written())
+	}
+	if got := listener.Metrics.Failed.Load(); got != 2 {
+		t.Errorf("Expected 2 failed calls, got %d", got)
+	}
+	conn.Close()
+}
+
+func TestStompErrorMapper(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	mapper := func(c *structures.FunctionCall, err error) *structures.FuncResponse {
+		return &structures.FuncResponse{MessageType: 3, Message: "mapped: " + err.Error(), Complete: true}
+	}
+	if err := Stomp.ErrorMapper(mapper)(listener); err != nil {
+		t.Fatalf("Failed to apply option: %v", err)
+	}
+	if err := listener.Listen(LoggingResponse); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+	conn.Feed([]byte("MESSAGE\nsubscription:actions.123.unit-test\nmessage-id:m1\ncorrelation-id:m1\n\nnot json\x00"))
+	WaitWritten(t, conn, `"message":"mapped: invalid character`)
+	conn.Close()
+}
+
+func TestStompErrorMapperPanic(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	listener.ErrorMapper = func(*structures.FunctionCall, error) *structures.FuncResponse {
+		panic("mapper broken")
+	}
+	failing := func(*structures.FunctionCall) (*structures.FuncResponse, error) {
+		return nil, errors.New("handler failed")
+	}
+	if err := listener.Listen(failing); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+	conn.Feed(FunctionMessage("m1", "fn"))
+	WaitWritten(t, conn, `"message":"Error occured: handler failed"`)
+	if panicked := listener.Metrics.Panicked.Load(); panicked != 1 {
+		t.Errorf("Expected the mapper panic to be counted, got: %d", panicked)
+	}
+	conn.Close()
+}
+
+func TestStompHandlerPanic(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	panicking := func(c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		if c.Function.Name == "broken" {
+			panic("boom")
+		}
+		return nil, nil
+	}
+	if err := listener.Listen(panicking, CompletedResponse); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+
+	conn.Feed(FunctionMessage("m1", "broken"))
+	conn.Feed(FunctionMessage("m2", "working"))
+	WaitWritten(t, conn, `"message":"Error occured: Function handler panicked: boom"`)
+	WaitWritten(t, conn, `"message":"App function completed"`)
+
+	deadline := time.Now().Add(time.Second)
+	for listener.Metrics.Succeeded.Load()+listener.Metrics.Failed.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := listener.Metrics.Received.Load(); got != 2 {
+		t.Errorf("Expected 2 received calls, got %d", got)
+	}
+	if got := listener.Metrics.Panicked.Load(); got != 1 {
+		t.Errorf("Expected 1 panicked call, got %d", got)
+	}
+	if got := listener.Metrics.Failed.Load(); got != 1 {
+		t.Errorf("Expected 1 failed call, got %d", got)
+	}
+	if got := listener.Metrics.Succeeded.Load(); got != 1 {
+		t.Errorf("Expected 1 succeeded call, got %d", got)
+	}
+	conn.Close()
+}
+
+func TestStompFunctionTimeout(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	listener.Timeout = time.Hour
+	listener.FunctionTimeouts = map[string]time.Duration{"slow": 10 * time.Millisecond}
+	waiting := func(ctx context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if err := listener.ListenContext(waiting); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+	conn.Feed(FunctionMessage("m1", "slow"))
+	WaitWritten(t, conn, `"message":"Error occured: context deadline exceeded"`)
+	conn.Close()
+}
+
+func TestStompShutdownDrains(t *testing.T) {
+	conn := NewTestConn()
+	conn.AutoReceipt = true
+	listener := NewTestListener(conn)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := func(*structures.FunctionCall) (*structures.FuncResponse, error) {
+		close(started)
+		<-release
+		return CompletedResponse(nil)
+	}
+	if err := listener.Listen(blocking); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+	conn.Feed(FunctionMessage("m1", "fn"))
+	<-started
+
+	type result struct {
+		aborted []AbortedCall
+		err     error
+	}
+	done := make(chan result)
+	go func() {
+		aborted, err := listener.Shutdown(context.Background())
+		done <- result{aborted, err}
+	}()
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before the in-flight call finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+
+	r := <-done
+	if r.err != nil || len(r.aborted) != 0 {
+		t.Fatalf("Expected clean shutdown, got %v, %v", r.aborted, r.err)
+	}
+	written := conn.Written()
+	response := bytes.Index(written, []byte("correlation-id:m1"))
+	unsubscribe := bytes.Index(written, []byte("UNSUBSCRIBE"))
+	if response < 0 || unsubscribe < response || !bytes.Contains(written, []byte("DISCONNECT")) {
+		t.Errorf("Expected response, then unsubscribe and disconnect, got: %q", written)
+	}
+}
+
+func TestStompShutdownAborts(t *testing.T) {
+	conn := NewTestConn()
+	conn.AutoReceipt = true
+	listener := NewTestListener(conn)
+	started := make(chan struct{})
+	waiting := func(ctx context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if err := listener.ListenContext(waiting); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+	conn.Feed(FunctionMessage("m1", "slow"))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	aborted, err := listener.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got: %v", err)
+	}
+	if len(aborted) != 1 || aborted[0].CorrelationID != "m1" || aborted[0].Call.Function.Name != "slow" {
+		t.Errorf("Expected the slow call to be aborted, got: %+v", aborted)
+	}
+}
+
+func TestStompShutdownAfterFailedListen(t *testing.T) {
+	listener := NewTestListener()
+	if err := listener.Listen(CompletedResponse); err == nil {
+		t.Fatal("Expected dial error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	returned := make(chan error, 1)
+	go func() {
+		_, err := listener.Shutdown(ctx)
+		returned <- err
+	}()
+	select {
+	case err := <-returned:
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown of a listener that failed to start hangs")
+	}
+
+	runtime := &Runtime{Listener: NewTestListener()}
+	if err := runtime.Run(context.Background()); err == nil {
+		t.Fatal("Expected dial error")
+	}
+	go func() {
+		_, err := runtime.Shutdown(ctx)
+		returned <- err
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown of a runtime that failed to run hangs")
+	}
+}
+
+func TestStompShutdownWhileDialing(t *testing.T) {
+	conn := NewTestConn()
+	conn.AutoReceipt = true
+	listener := NewTestListener()
+	dialing := make(chan struct{})
+	gate := make(chan struct{})
+	listener.dial = func() (net.Conn, error) {
+		close(dialing)
+		<-gate
+		return conn, nil
+	}
+	listened := make(chan error, 1)
+	go func() { listened <- listener.Listen(CompletedResponse) }()
+	<-dialing
+
+	returned := make(chan error, 1)
+	go func() {
+		_, err := listener.Shutdown(context.Background())
+		returned <- err
+	}()
+	select {
+	case <-returned:
+		t.Fatal("Shutdown returned before the listener finished starting")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(gate)
+
+	if err := <-listened; err == nil {
+		t.Error("Expected listening to fail once shut down")
+	}
+	select {
+	case err := <-returned:
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown during dial hangs")
+	}
+	if !bytes.Contains(conn.Written(), []byte("DISCONNECT")) {
+		t.Errorf("Expected the connection to be closed, got: %q", conn.Written())
+	}
+	select {
+	case <-listener.Done:
+		t.Error("Expected no supervisor to be started")
+	default:
+	}
+}
+
+func TestStompStatusReporter(t *testing.T) {
+	conn := NewTestConn()
+	listener := NewTestListener(conn)
+	listener.StatusInterval = 100 * time.Millisecond
+	progress := func(ctx context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		for i := range 3 {
+			StatusReporterFrom(ctx).Statusf("Downloaded %d/3 files", i+1)
+		}
+		if c.Function.Name == "slow" {
+			time.Sleep(150 * time.Millisecond)
+		}
+		ReportStatus(ctx, "Uploading")
+		return CompletedResponse(c)
+	}
+	if err := listener.ListenContext(progress); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	WaitWritten(t, conn, testSubscribe)
+
+	conn.Feed(FunctionMessage("m1", "slow"))
+	WaitWritten(t, conn, "App function completed")
+	written := string(conn.Written())
+	for _, expected := range []string{
+		`{"message_type":0,"message":"Downloaded 1/3 files","complete":false}`,
+		`{"message_type":0,"message":"Downloaded 3/3 files","complete":false}`,
+	} {
+		if !strings.Contains(written, expected) {
+			t.Errorf("Expected status %s, got: %q", expected, written)
+		}
+	}
+	for _, unexpected := range []string{"Downloaded 2/3 files", "Uploading"} {
+		if strings.Contains(written, unexpected) {
+			t.Errorf("Expected %q to be coalesced or dropped, got: %q", unexpected, written)
+		}
+	}
+	if strings.Index(written, "Downloaded 3/3 files") > strings.Index(written, "App function completed") {
+		t.Errorf("Expected status updates before the final response, got: %q", written)
+	}
+	conn.Close()
+}
+
+func TestNewStompListenerAccessCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		apiKeys  []int
+		expected error
+	}{
+		{"available", 200, []int{42}, nil},
+		{"not a user", 200, []int{7}, ErrAPIKeyNotAuthorized},
+		{"missing", 404, nil, ErrDestinationNotFound},
+		{"forbidden", 403, nil, ErrAPIKeyNotAuthorized},
+		{"bad credentials", 401, nil, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &HTTPClient{
+				Session: &structures.SessionResponseJson{APIKeyHandle: 42},
+				Org:     &structures.Org{ID: 123},
+				Client: http.Client{
+					Transport: &mockRoundTripper{
+						roundTripFunc: func(req *http.Request) *http.Response {
+							body, _ := json.Marshal(structures.MessageDestination{APIKeys: tt.apiKeys})
+							return &http.Response{
+								StatusCode: tt.status,
+								Body:       io.NopCloser(bytes.NewReader(body)),
+								Header:     make(http.Header),
+							}
+						},
+					},
+				},
+				Ctx: context.Background(),
+			}
+			_, err := NewStompListener(client, Stomp.MessageDestination("unit-test"))
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
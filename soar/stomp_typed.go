@@ -0,0 +1,102 @@
+package soar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+// Function logic with decoded inputs and a typed result, see Typed and Register
+type TypedHandler[In, Out any] func(context.Context, *structures.FunctionCall, In) (Out, error)
+
+// Adapts a TypedHandler to ContextHandler: inputs are decoded into In and checked for
+// fields tagged `input:"required"`, the result is reported as Results.Content along with
+// the call inputs and execution metrics
+func Typed[In, Out any](f TypedHandler[In, Out]) ContextHandler {
+	return func(ctx context.Context, c *structures.FunctionCall) (*structures.FuncResponse, error) {
+		start := time.Now()
+		inputs, err := LoadInputs[In](c)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode function inputs: %w", err)
+		}
+		if err := checkRequired(c.Inputs, *inputs); err != nil {
+			return nil, err
+		}
+		out, err := f(ctx, c, *inputs)
+		if err != nil {
+			return nil, err
+		}
+		return &structures.FuncResponse{
+			MessageType: 0,
+			Message:     "App function completed",
+			Complete:    true,
+			Results: &structures.Results{
+				Version: 2.0,
+				Success: true,
+				Content: out,
+				Inputs:  c.Inputs,
+				Metrics: newMetrics(start),
+			},
+		}, nil
+	}
+}
+
+// Registers a TypedHandler in the dispatcher by function api name
+func Register[In, Out any](l *FunctionLookup, name string, f TypedHandler[In, Out], mw ...Middleware) {
+	l.Register(name, Typed(f), mw...)
+}
+
+// Reports fields tagged `input:"required"` that are absent or null in the raw inputs
+func checkRequired(raw any, inputs any) error {
+	t := reflect.TypeOf(inputs)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	values, _ := raw.(map[string]any)
+	var errs []error
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Tag.Get("input") != "required" {
+			continue
+		}
+		name := jsonName(field)
+		if v, ok := values[name]; !ok || v == nil {
+			errs = append(errs, fmt.Errorf("Required input %s is missing", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Key of a struct field in JSON, as encoding/json resolves it
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// Execution metrics in the format of resilient-circuits results
+func newMetrics(start time.Time) structures.Metrics {
+	m := structures.Metrics{
+		Version:         "1.0",
+		ExecutionTimeMs: int(time.Since(start).Milliseconds()),
+		Timestamp:       time.Now().Format(time.DateTime),
+	}
+	m.Host, _ = os.Hostname()
+	if info, ok := debug.ReadBuildInfo(); ok {
+		m.Package = info.Main.Path
+		m.PackageVersion = info.Main.Version
+	}
+	return m
+}
@@ -0,0 +1,59 @@
+package soar
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chmele/ibm-soar/soar/structures"
+)
+
+type lookupInputs struct {
+	Address string `json:"address" input:"required"`
+	Limit   int    `json:"limit"`
+}
+
+type lookupResult struct {
+	Hits []string `json:"hits"`
+}
+
+func lookupHandler(ctx context.Context, c *structures.FunctionCall, in lookupInputs) (lookupResult, error) {
+	return lookupResult{Hits: []string{in.Address, strings.Repeat("x", in.Limit)}}, nil
+}
+
+func TestTypedHandler(t *testing.T) {
+	lookup := NewFunctionLookup()
+	Register(lookup, "lookup", lookupHandler)
+
+	inputs := map[string]any{"address": "10.0.0.1", "limit": float64(2)}
+	fr, err := lookup.Handler(&structures.FunctionCall{
+		Function: structures.Function{Name: "lookup"},
+		Inputs:   inputs,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !fr.Complete || fr.Results == nil || !fr.Results.Success {
+		t.Fatalf("Expected successful completion, got %+v", fr)
+	}
+	result, ok := fr.Results.Content.(lookupResult)
+	if !ok || len(result.Hits) != 2 || result.Hits[0] != "10.0.0.1" || result.Hits[1] != "xx" {
+		t.Errorf("Unexpected content: %#v", fr.Results.Content)
+	}
+	if in, ok := fr.Results.Inputs.(map[string]any); !ok || in["address"] != "10.0.0.1" {
+		t.Errorf("Expected raw inputs in results, got %#v", fr.Results.Inputs)
+	}
+	if fr.Results.Metrics.Timestamp == "" {
+		t.Error("Expected metrics to be filled")
+	}
+}
+
+func TestTypedHandlerRequiredInput(t *testing.T) {
+	handler := Typed(lookupHandler)
+	for _, inputs := range []map[string]any{{"limit": float64(1)}, {"address": nil}} {
+		_, err := handler(context.Background(), &structures.FunctionCall{Inputs: inputs})
+		if err == nil || !strings.Contains(err.Error(), "Required input address is missing") {
+			t.Errorf("Expected missing input error for %v, got: %v", inputs, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package structures
+
+// Incident artifact, Type is the artifact type ID (see ArtifactType)
+type Artifact struct {
+	ID          int          `json:"id,omitempty"`
+	Type        int          `json:"type"`
+	Value       string       `json:"value"`
+	Description *TextContent `json:"description,omitempty"`
+	IncID       int          `json:"inc_id,omitempty"`
+	ParentID    int          `json:"parent_id,omitempty"`
+	// Whether the artifact is used to relate incidents, SOAR treats nil as true
+	Relating         *bool              `json:"relating,omitempty"`
+	Created          int64              `json:"created,omitempty"`
+	LastModifiedTime int64              `json:"last_modified_time,omitempty"`
+	Creator          *Principal         `json:"creator_principal,omitempty"`
+	Properties       []ArtifactProperty `json:"properties,omitempty"`
+	Attachment       *Attachment        `json:"attachment,omitempty"`
+}
+
+type ArtifactProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// File of an artifact or an incident/task attachment
+type Attachment struct {
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Created     int64  `json:"created,omitempty"`
+	IncID       int    `json:"inc_id,omitempty"`
+	TaskID      int    `json:"task_id,omitempty"`
+}
+
+// Artifact type such as "IP Address" or "Malware MD5 Hash"
+type ArtifactType struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	ProgrammaticName string `json:"programmatic_name"`
+	Description      string `json:"desc"`
+	Enabled          bool   `json:"enabled"`
+	System           bool   `json:"system"`
+}
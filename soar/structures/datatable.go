@@ -0,0 +1,19 @@
+package structures
+
+// Rows of an incident data table
+type DataTable struct {
+	ID   int            `json:"id"`
+	Rows []DataTableRow `json:"rows"`
+}
+
+type DataTableRow struct {
+	ID int `json:"id,omitempty"`
+	// Cells by column API name
+	Cells map[string]Cell `json:"cells"`
+}
+
+// Cell value: dates are milliseconds since epoch, selects are option IDs
+type Cell struct {
+	ID    string `json:"id,omitempty"`
+	Value any    `json:"value"`
+}
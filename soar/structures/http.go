@@ -6,6 +6,7 @@ type SessionResponseJson struct {
 	APIKeyHandle           int    `json:"api_key_handle"`
 	ClientID               string `json:"client_id"`
 	DisplayName            string `json:"display_name"`
+	CSRFToken              string `json:"csrf_token"`
 }
 type LastModifiedBy struct {
 	ID          int    `json:"id"`
@@ -71,3 +72,17 @@ type InboundDestination struct {
 	Version         int    `json:"version"`
 	ExportKey       string `json:"export_key"`
 }
+
+// Function definition as returned by the functions endpoint
+type FunctionDefinition struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	DisplayName       string `json:"display_name"`
+	UUID              string `json:"uuid"`
+	DestinationHandle int    `json:"destination_handle"`
+	Version           int    `json:"version"`
+}
+
+type FunctionList struct {
+	Entities []FunctionDefinition `json:"entities"`
+}
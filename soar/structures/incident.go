@@ -0,0 +1,126 @@
+package structures
+
+import "encoding/json"
+
+// Incident as returned by the incidents endpoint, dates are milliseconds since epoch
+type Incident struct {
+	ID                int          `json:"id,omitempty"`
+	OrgID             int          `json:"org_id,omitempty"`
+	Name              string       `json:"name"`
+	Description       *TextContent `json:"description,omitempty"`
+	DiscoveredDate    int64        `json:"discovered_date,omitempty"`
+	CreateDate        int64        `json:"create_date,omitempty"`
+	StartDate         int64        `json:"start_date,omitempty"`
+	DueDate           int64        `json:"due_date,omitempty"`
+	EndDate           int64        `json:"end_date,omitempty"`
+	IncTraining       bool         `json:"inc_training,omitempty"`
+	Confirmed         bool         `json:"confirmed,omitempty"`
+	PlanStatus        string       `json:"plan_status,omitempty"`
+	SeverityCode      int          `json:"severity_code,omitempty"`
+	OwnerID           int          `json:"owner_id,omitempty"`
+	PhaseID           int          `json:"phase_id,omitempty"`
+	ResolutionID      int          `json:"resolution_id,omitempty"`
+	ResolutionSummary string       `json:"resolution_summary,omitempty"`
+	IncidentTypeIDs   []int        `json:"incident_type_ids,omitempty"`
+	Members           []int        `json:"members,omitempty"`
+	ExposureTypeID    int          `json:"exposure_type_id,omitempty"`
+	City              string       `json:"city,omitempty"`
+	Country           int          `json:"country,omitempty"`
+	Version           int          `json:"vers,omitempty"`
+	Creator           *Principal   `json:"creator,omitempty"`
+	// Custom incident fields by API name
+	Properties map[string]any `json:"properties,omitempty"`
+	// JSON the incident was decoded from, all fields included (also the ones not modeled above)
+	Raw json.RawMessage `json:"-"`
+}
+
+func (i *Incident) UnmarshalJSON(data []byte) error {
+	type plain Incident
+	if err := json.Unmarshal(data, (*plain)(i)); err != nil {
+		return err
+	}
+	i.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Rich text value, SOAR returns it either as a plain string or as a format/content object
+type TextContent struct {
+	// "text" or "html"
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+func (t *TextContent) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = TextContent{Format: "html", Content: s}
+		return nil
+	}
+	type plain TextContent
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+// Incident plan status values
+const (
+	PlanStatusActive = "A"
+	PlanStatusClosed = "C"
+)
+
+// Field of an object type (incident, task, ...) as defined in the organization layout
+type FieldDefinition struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Text      string `json:"text"`
+	InputType string `json:"input_type"`
+	// "always", "close" or empty
+	Required string `json:"required"`
+	// "properties" for custom fields
+	Prefix   string       `json:"prefix"`
+	Internal bool         `json:"internal"`
+	Values   []FieldValue `json:"values"`
+}
+
+// Option of a select or multiselect field
+type FieldValue struct {
+	Value   int    `json:"value"`
+	Label   string `json:"label"`
+	Enabled bool   `json:"enabled"`
+	Hidden  bool   `json:"hidden"`
+}
+
+// Single field change of a PATCH request, old value is used by SOAR for conflict detection
+type FieldChange struct {
+	Field    FieldName      `json:"field"`
+	OldValue *FieldValueDTO `json:"old_value,omitempty"`
+	NewValue *FieldValueDTO `json:"new_value"`
+}
+
+type FieldName struct {
+	Name string `json:"name"`
+}
+
+type FieldValueDTO struct {
+	Object any `json:"object"`
+}
+
+// Body of a PATCH request
+type Patch struct {
+	Changes []FieldChange `json:"changes"`
+	Version int           `json:"version,omitempty"`
+}
+
+// Result of a PATCH request, unsuccessful when fields were changed by someone else meanwhile
+type PatchStatus struct {
+	Success       bool           `json:"success"`
+	Title         string         `json:"title"`
+	Message       string         `json:"message"`
+	Hints         []string       `json:"hints"`
+	FieldFailures []FieldFailure `json:"field_failures"`
+}
+
+// Field whose current value differs from the old value of the change
+type FieldFailure struct {
+	Field              FieldName `json:"field"`
+	YourOriginalValue  any       `json:"your_original_value"`
+	ActualCurrentValue any       `json:"actual_current_value"`
+}
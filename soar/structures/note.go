@@ -0,0 +1,17 @@
+package structures
+
+// Incident or task note (comment), replies are nested in Children
+type Note struct {
+	ID         int          `json:"id,omitempty"`
+	ParentID   int          `json:"parent_id,omitempty"`
+	Text       *TextContent `json:"text"`
+	UserID     int          `json:"user_id,omitempty"`
+	UserFname  string       `json:"user_fname,omitempty"`
+	UserLname  string       `json:"user_lname,omitempty"`
+	CreateDate int64        `json:"create_date,omitempty"`
+	ModifyDate int64        `json:"modify_date,omitempty"`
+	IncID      int          `json:"inc_id,omitempty"`
+	TaskID     int          `json:"task_id,omitempty"`
+	IsDeleted  bool         `json:"is_deleted,omitempty"`
+	Children   []Note       `json:"children,omitempty"`
+}
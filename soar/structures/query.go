@@ -0,0 +1,53 @@
+package structures
+
+import "encoding/json"
+
+// Body of a query_paged request; conditions of a filter are ANDed, filters are ORed
+type QueryPaged struct {
+	Filters []Filter `json:"filters,omitempty"`
+	Sorts   []Sort   `json:"sorts,omitempty"`
+	Start   int      `json:"start"`
+	Length  int      `json:"length"`
+}
+
+type Filter struct {
+	Conditions []Condition `json:"conditions"`
+}
+
+type Condition struct {
+	FieldName string `json:"field_name"`
+	Method    string `json:"method"`
+	Value     any    `json:"value,omitempty"`
+}
+
+type Sort struct {
+	FieldName string `json:"field_name"`
+	// "asc" or "desc"
+	Type string `json:"type"`
+}
+
+// Page of query_paged results
+type QueryPagedResult[T any] struct {
+	RecordsTotal    int `json:"recordsTotal"`
+	RecordsFiltered int `json:"recordsFiltered"`
+	Data            []T `json:"data"`
+}
+
+// List of objects, SOAR returns it either as a plain array or wrapped into {"entities": [...]}
+type EntityList[T any] []T
+
+func (l *EntityList[T]) UnmarshalJSON(data []byte) error {
+	var list []T
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var wrapped struct {
+		Entities []T `json:"entities"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return err
+	}
+	*l = wrapped.Entities
+	return nil
+}
@@ -152,6 +152,6 @@ type Results struct {
 	Reason  any     `json:"reason"`
 	Content any     `json:"content"`
 	Raw     any     `json:"raw"`
-	Inputs  Inputs  `json:"inputs"`
+	Inputs  any     `json:"inputs"`
 	Metrics Metrics `json:"metrics"`
 }
@@ -0,0 +1,61 @@
+package structures
+
+import "encoding/json"
+
+// Incident task, dates are milliseconds since epoch
+type Task struct {
+	ID           int          `json:"id,omitempty"`
+	Name         string       `json:"name"`
+	IncID        int          `json:"inc_id,omitempty"`
+	IncName      string       `json:"inc_name,omitempty"`
+	Instructions *TextContent `json:"instructions,omitempty"`
+	PhaseID      int          `json:"phase_id,omitempty"`
+	OwnerID      int          `json:"owner_id,omitempty"`
+	Members      []int        `json:"members,omitempty"`
+	DueDate      int64        `json:"due_date,omitempty"`
+	InitDate     int64        `json:"init_date,omitempty"`
+	ClosedDate   int64        `json:"closed_date,omitempty"`
+	// TaskStatusOpen or TaskStatusClosed
+	Status   string `json:"status,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Active   bool   `json:"active,omitempty"`
+	Category string `json:"cat_name,omitempty"`
+	Custom   bool   `json:"custom,omitempty"`
+	Version  int    `json:"vers,omitempty"`
+	// Custom task fields by API name
+	Properties map[string]any `json:"properties,omitempty"`
+	// JSON the task was decoded from, all fields included (also the ones not modeled above)
+	Raw json.RawMessage `json:"-"`
+}
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type plain Task
+	if err := json.Unmarshal(data, (*plain)(t)); err != nil {
+		return err
+	}
+	t.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Task status values
+const (
+	TaskStatusOpen   = "O"
+	TaskStatusClosed = "C"
+)
+
+// Incident phase such as "Respond" or "Engage"
+type Phase struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Order   int    `json:"order"`
+	Enabled bool   `json:"enabled"`
+}
+
+// SOAR user, a possible task or incident owner
+type User struct {
+	ID        int    `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"fname"`
+	LastName  string `json:"lname"`
+	Status    string `json:"status"`
+}